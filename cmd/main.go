@@ -12,12 +12,17 @@ import (
 )
 
 var (
-	kubeconfig string
-	ver        bool
+	flags = &operator.Flags{}
+	ver   bool
 )
 
 func parserFlags() {
-	flag.StringVarP(&kubeconfig, "kubeconfig", "", "", "Absolute path to the kubeconfig file.")
+	flag.StringVarP(&flags.Kubeconfig, "kubeconfig", "", "", "Absolute path to the kubeconfig file.")
+	flag.StringVarP(&flags.ConfigFile, "config", "", "", "Path to the operator config file, it is reloaded when changed.")
+	flag.StringVarP(&flags.WebhookBindAddress, "webhook-bind-address", "", ":8443", "The address the admission webhook binds to.")
+	flag.StringVarP(&flags.TLSCertFile, "tls-cert-file", "", "", "File containing the x509 certificate for the admission webhook.")
+	flag.StringVarP(&flags.TLSPrivateKeyFile, "tls-private-key-file", "", "", "File containing the x509 private key matching --tls-cert-file.")
+	flag.StringVarP(&flags.MetricsBindAddress, "metrics-bind-address", "", ":8080", "The address the metrics endpoint binds to, empty to disable it.")
 	flag.BoolVarP(&ver, "version", "", false, "Display the version.")
 	flag.CommandLine.AddGoFlagSet(goflag.CommandLine)
 	flag.Parse()
@@ -34,8 +39,8 @@ func main() {
 		os.Exit(0)
 	}
 
-	op := operator.NewMainOperator()
-	if err := op.Initialize(kubeconfig); err != nil {
+	op := operator.NewMainOperator(flags)
+	if err := op.Initialize(); err != nil {
 		klog.Fatalf("Error initing operator instance: %+v.", err)
 	}
 
@@ -10,5 +10,5 @@ CODEGEN_PKG=${CODEGEN_PKG:-$(cd "${SCRIPT_ROOT}"; ls -d -1 ./vendor/k8s.io/code-
 ${CODEGEN_PKG}/generate-groups.sh "deepcopy,client,informer,lister" \
   github.com/kairen/line-bot-operator/pkg/generated \
   github.com/kairen/line-bot-operator/pkg/apis \
-  "line:v1alpha1" \
+  "line:v1alpha1,v1beta1" \
   --output-base "$(dirname ${BASH_SOURCE})/../../../../" 
\ No newline at end of file
@@ -0,0 +1,24 @@
+package v1alpha1
+
+import (
+	"github.com/kairen/line-bot-operator/pkg/version"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SetBotDefaults fills the unset fields of a Bot spec. The version follows the operator
+// version, the expose type falls back to Ngrok and the selector selects "bot: <name>".
+func SetBotDefaults(bot *Bot) {
+	if bot.Spec.Version == "" {
+		bot.Spec.Version = version.GetVersion()
+	}
+
+	if bot.Spec.Expose.Type == "" {
+		bot.Spec.Expose.Type = NgrokExpose
+	}
+
+	if bot.Spec.Selector == nil {
+		bot.Spec.Selector = &metav1.LabelSelector{
+			MatchLabels: map[string]string{"bot": bot.Name},
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package v1alpha1
+
+import "github.com/line/line-bot-sdk-go/linebot"
+
+// The subsets of an EventBinding are kept in resolution order, a bot replies with
+// the first subset matching a webhook event. The subsets are ordered by:
+//   1. the higher priority,
+//   2. the more specific match, message events with keywords or patterns come
+//      before the ones replying to any message,
+//   3. the event name,
+//   4. the origin, Events come before ClusterEvents of the same name.
+
+// Specificity returns how specific the messages matched by a binding are.
+func (b *Binding) Specificity() int {
+	if b.Type != linebot.EventTypeMessage {
+		return 0
+	}
+
+	for _, msg := range b.Messages {
+		if len(msg.Keywords) > 0 || len(msg.Patterns) > 0 {
+			return 1
+		}
+	}
+	return 0
+}
+
+// BindingLess reports whether a is resolved before b.
+func BindingLess(a, b *Binding) bool {
+	if a.Priority != b.Priority {
+		return a.Priority > b.Priority
+	}
+	if a.Specificity() != b.Specificity() {
+		return a.Specificity() > b.Specificity()
+	}
+	if a.Name != b.Name {
+		return a.Name < b.Name
+	}
+	return !a.FromClusterEvent() && b.FromClusterEvent()
+}
+
+// BindingTies reports whether a and b are only ordered by their names and origins.
+func BindingTies(a, b *Binding) bool {
+	return a.Priority == b.Priority && a.Specificity() == b.Specificity()
+}
+
+// FromClusterEvent reports whether the binding is copied from a ClusterEvent,
+// bindings without an origin are copied from Events.
+func (b *Binding) FromClusterEvent() bool {
+	return b.Origin == ClusterEventOrigin
+}
+
+// Refers reports whether the binding is copied from the event of the origin and name.
+func (b *Binding) Refers(origin BindingOrigin, name string) bool {
+	return b.Name == name && b.FromClusterEvent() == (origin == ClusterEventOrigin)
+}
+
+// Reference returns the name of the event, prefixed by ClusterEvent/ for a ClusterEvent.
+func (b *Binding) Reference() string {
+	if b.FromClusterEvent() {
+		return string(ClusterEventOrigin) + "/" + b.Name
+	}
+	return b.Name
+}
@@ -44,6 +44,8 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&EventList{},
 		&EventBinding{},
 		&EventBindingList{},
+		&ClusterEvent{},
+		&ClusterEventList{},
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
@@ -2,6 +2,7 @@ package v1alpha1
 
 import (
 	"github.com/line/line-bot-sdk-go/linebot"
+	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -31,12 +32,94 @@ type BotExpose struct {
 	NgrokToken     string        `json:"ngrokToken"`
 }
 
+// BotResources are the compute resources of the bot containers, they override
+// the defaults of the operator config.
+type BotResources struct {
+	Bot   v1.ResourceRequirements `json:"bot,omitempty"`
+	Ngrok v1.ResourceRequirements `json:"ngrok,omitempty"`
+}
+
 type BotSpec struct {
 	Selector          *metav1.LabelSelector `json:"selector"`
 	ChannelSecretName string                `json:"channelSecretName"`
 	Expose            BotExpose             `json:"expose"`
 	Version           string                `json:"version"`
 	LogLevel          int                   `json:"logLevel"`
+	Resources         BotResources          `json:"resources,omitempty"`
+	// PodTemplate is merged into the generated pod template like a strategic merge
+	// patch, e.g. containers are merged by name.
+	PodTemplate *v1.PodTemplateSpec `json:"podTemplate,omitempty"`
+	// Replicas and Autoscaling are only supported by the Ingress and LoadBalancer
+	// exposes, an ngrok tunnel can't be shared by several pods.
+	Replicas    *int32          `json:"replicas,omitempty"`
+	Autoscaling *BotAutoscaling `json:"autoscaling,omitempty"`
+	// TokenSource lets the operator issue and rotate the channel access token, instead
+	// of reading a long-lived one from the channel secret.
+	TokenSource *BotTokenSource `json:"tokenSource,omitempty"`
+	// RateLimit limits the replies of the bot to protect the Messaging API quota.
+	RateLimit *BotRateLimit `json:"rateLimit,omitempty"`
+}
+
+// BotRateLimit limits the replies of a bot, each replica enforces the limits on
+// its own.
+type BotRateLimit struct {
+	// PerSource is a token bucket for each user, group or room.
+	PerSource *TokenBucket `json:"perSource,omitempty"`
+	// RepliesPerSecond limits all the replies of the bot, with up to Burst replies
+	// at once, RepliesPerSecond when unset. There is no limit when it is 0.
+	RepliesPerSecond int32 `json:"repliesPerSecond,omitempty"`
+	Burst            int32 `json:"burst,omitempty"`
+	// RuleCooldown is the time before a rule replies again in the same chat.
+	RuleCooldown *metav1.Duration `json:"ruleCooldown,omitempty"`
+	// Action is taken when a chat hits its bucket or a cooldown, Drop when unset.
+	// The replies over the global limit are always dropped.
+	Action RateLimitAction `json:"action,omitempty"`
+	// Notice is the text of the Notice action.
+	Notice string `json:"notice,omitempty"`
+}
+
+type RateLimitAction string
+
+const (
+	// RateLimitDrop doesn't reply.
+	RateLimitDrop RateLimitAction = "Drop"
+	// RateLimitNotice replies once with the notice, and drops the other replies
+	// until the chat is allowed again.
+	RateLimitNotice RateLimitAction = "Notice"
+)
+
+// TokenBucket allows up to Capacity replies at once, and one more each
+// RefillInterval.
+type TokenBucket struct {
+	Capacity       int32           `json:"capacity"`
+	RefillInterval metav1.Duration `json:"refillInterval"`
+}
+
+// BotAutoscaling creates a HorizontalPodAutoscaler for the bot deployment.
+type BotAutoscaling struct {
+	MinReplicas                    *int32 `json:"minReplicas,omitempty"`
+	MaxReplicas                    int32  `json:"maxReplicas"`
+	TargetCPUUtilizationPercentage *int32 `json:"targetCPUUtilizationPercentage,omitempty"`
+}
+
+type BotTokenSourceType string
+
+const (
+	// StaticTokenSource reads the channelToken key of the channel secret.
+	StaticTokenSource BotTokenSourceType = "Static"
+	// ClientSecretTokenSource issues short-lived tokens with the channel ID and secret.
+	ClientSecretTokenSource BotTokenSourceType = "ClientSecret"
+	// AssertionTokenSource issues v2.1 tokens with a channel assertion signed by the
+	// private key of PrivateKeySecretName.
+	AssertionTokenSource BotTokenSourceType = "Assertion"
+)
+
+type BotTokenSource struct {
+	Type                 BotTokenSourceType `json:"type"`
+	ChannelID            string             `json:"channelID"`
+	PrivateKeySecretName string             `json:"privateKeySecretName,omitempty"`
+	// TokenLifetime is the lifetime of the Assertion tokens, 30 days at most.
+	TokenLifetime *metav1.Duration `json:"tokenLifetime,omitempty"`
 }
 
 type BotPhase string
@@ -52,6 +135,47 @@ type BotStatus struct {
 	Phase          BotPhase    `json:"phase"`
 	Reason         string      `json:"reason,omitempty"`
 	LastUpdateTime metav1.Time `json:"lastUpdateTime"`
+	// Quota and Insights are polled from the Messaging API for the active bots.
+	Quota      *BotQuota      `json:"quota,omitempty"`
+	Insights   *BotInsights   `json:"insights,omitempty"`
+	Conditions []BotCondition `json:"conditions,omitempty"`
+}
+
+// BotQuota is the message quota of the current month.
+type BotQuota struct {
+	// Type is limited for the plans with a monthly limit, or none.
+	Type       string      `json:"type"`
+	Limit      int64       `json:"limit,omitempty"`
+	TotalUsage int64       `json:"totalUsage"`
+	PollTime   metav1.Time `json:"pollTime"`
+}
+
+// BotInsights are the statistics of a day, the day before the poll.
+type BotInsights struct {
+	Date            string `json:"date"`
+	Followers       int64  `json:"followers"`
+	TargetedReaches int64  `json:"targetedReaches"`
+	Blocks          int64  `json:"blocks"`
+	APIReply        int64  `json:"apiReply"`
+	APIPush         int64  `json:"apiPush"`
+	APIMulticast    int64  `json:"apiMulticast"`
+	APIBroadcast    int64  `json:"apiBroadcast"`
+}
+
+type BotConditionType string
+
+const (
+	// BotQuotaNearlyExhausted is true when the usage of the message quota reaches
+	// the threshold of the operator config.
+	BotQuotaNearlyExhausted BotConditionType = "QuotaNearlyExhausted"
+)
+
+type BotCondition struct {
+	Type               BotConditionType   `json:"type"`
+	Status             v1.ConditionStatus `json:"status"`
+	LastTransitionTime metav1.Time        `json:"lastTransitionTime,omitempty"`
+	Reason             string             `json:"reason,omitempty"`
+	Message            string             `json:"message,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -64,26 +188,154 @@ type BotList struct {
 }
 
 // +genclient
-// +genclient:noStatus
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 type Event struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata"`
 
-	Spec EventSpec `json:"spec"`
+	Spec   EventSpec   `json:"spec"`
+	Status EventStatus `json:"status,omitempty"`
+}
+
+// EventStatus is written by the operator to show where the event is used.
+type EventStatus struct {
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Bindings are the names of the EventBindings holding the event, they are
+	// named after their Bot.
+	Bindings []string `json:"bindings,omitempty"`
+	// Errors are the validation errors of the spec, e.g. for events created while
+	// the admission webhook was not installed.
+	Errors []string `json:"errors,omitempty"`
+	// Conflicts are the keywords also used by other events bound to the same Bot.
+	Conflicts []EventConflict `json:"conflicts,omitempty"`
+	// Active tells whether the schedule of the event is active now, it is always
+	// true without a schedule.
+	Active bool `json:"active"`
+}
+
+type EventConflict struct {
+	Keyword string `json:"keyword"`
+	Event   string `json:"event"`
+	Bot     string `json:"bot"`
+	// Tie is set when both events have the same priority and specificity, so the
+	// reply is only decided by the event names.
+	Tie bool `json:"tie,omitempty"`
 }
 
 type Message struct {
 	Type     linebot.MessageType `json:"type"`
 	Keywords []string            `json:"keywords,omitempty"`
-	Reply    string              `json:"reply"`
+	// Patterns are regular expressions matched against the text of the message,
+	// while the keywords have to be equal to it.
+	Patterns []string `json:"patterns,omitempty"`
+	Reply    string   `json:"reply"`
+	// Replies are weighted variants used instead of the reply, one of them is
+	// picked for each reply by the selection strategy.
+	Replies   []ReplyVariant  `json:"replies,omitempty"`
+	Selection *ReplySelection `json:"selection,omitempty"`
+	// I18n localizes the reply by the language of the user.
+	I18n *MessageI18n `json:"i18n,omitempty"`
+}
+
+// MessageI18n has the replies of a message by locale, the language of the user
+// profile picks one, e.g. zh-TW, or ja for ja-JP. The replies of all the locales
+// must have the same ${name} placeholders.
+type MessageI18n struct {
+	Replies map[string]string `json:"replies,omitempty"`
+	// Fallback is the locale of the languages without a reply, the reply of the
+	// message is used when unset.
+	Fallback string `json:"fallback,omitempty"`
+	// ConfigMapName adds the replies of translation bundles, each key of the
+	// ConfigMap is a locale with a YAML map of bundle keys to texts. It is read in
+	// the namespace of the EventBinding.
+	ConfigMapName string `json:"configMapName,omitempty"`
+	// Key is the bundle key of the message.
+	Key string `json:"key,omitempty"`
+}
+
+type ReplyVariant struct {
+	// Weight is the relative chance or share of the variant, 1 when unset.
+	Weight int32  `json:"weight,omitempty"`
+	Reply  string `json:"reply"`
+}
+
+type SelectionStrategy string
+
+const (
+	// SelectionRandom picks a variant at random by weight.
+	SelectionRandom SelectionStrategy = "Random"
+	// SelectionRoundRobin cycles through the variants for each user, a variant is
+	// sent as many times in a row as its weight.
+	SelectionRoundRobin SelectionStrategy = "RoundRobin"
+	// SelectionNoRepeat picks a variant at random by weight among the ones not
+	// sent to the user in the last replies of the window.
+	SelectionNoRepeat SelectionStrategy = "NoRepeat"
+)
+
+type ReplySelection struct {
+	// Strategy is Random when unset.
+	Strategy SelectionStrategy `json:"strategy,omitempty"`
+	// Window is the number of last variants the NoRepeat strategy doesn't send
+	// again to a user, 1 when unset.
+	Window int32 `json:"window,omitempty"`
+	// Seed makes the random picks of the bot deterministic, e.g. for tests.
+	Seed *int64 `json:"seed,omitempty"`
 }
 
 type EventSpec struct {
 	Selector *metav1.LabelSelector `json:"selector"`
 	Type     linebot.EventType     `json:"type"`
 	Messages []Message             `json:"messages"`
+	// Priority orders the events bound to the same bot, see the resolution order
+	// of the EventBinding subsets.
+	Priority int32 `json:"priority,omitempty"`
+	// Source limits the chats the event replies in, all the chats when nil.
+	Source *EventSource `json:"source,omitempty"`
+	// MentionRequired only replies in groups and rooms when the bot is mentioned.
+	MentionRequired bool `json:"mentionRequired,omitempty"`
+	// Schedule limits when the event replies, all the time when nil.
+	Schedule *EventSchedule `json:"schedule,omitempty"`
+}
+
+// EventSource filters the chats by source type and by user, group or room IDs.
+// A chat is denied when its user or chat ID is denied, and when an allow list is
+// set, one of them has to be allowed.
+type EventSource struct {
+	Types []linebot.EventSourceType `json:"types,omitempty"`
+	Allow []string                  `json:"allow,omitempty"`
+	Deny  []string                  `json:"deny,omitempty"`
+	// ConfigMapName adds the IDs of the allow and deny keys of a ConfigMap, one per
+	// line. It is read in the namespace of the EventBinding.
+	ConfigMapName string `json:"configMapName,omitempty"`
+}
+
+// EventSchedule is the time an event is active: a day between the start and end
+// dates, all day or never on holidays, and otherwise within one of the windows.
+type EventSchedule struct {
+	// TimeZone is the IANA time zone of the windows and dates, e.g. Asia/Taipei,
+	// UTC when empty.
+	TimeZone string `json:"timeZone,omitempty"`
+	// Windows are cron-like expressions of the active minutes, with the minute,
+	// hour, day of month, month and day of week fields, e.g. "* 18-23 * * 1-5" for
+	// weekday evenings. The event is active all day without windows.
+	Windows []string `json:"windows,omitempty"`
+	// StartDate and EndDate bound the active days as YYYY-MM-DD, both included.
+	StartDate string            `json:"startDate,omitempty"`
+	EndDate   string            `json:"endDate,omitempty"`
+	Holidays  *ScheduleHolidays `json:"holidays,omitempty"`
+}
+
+// ScheduleHolidays overrides the windows on holidays.
+type ScheduleHolidays struct {
+	// Dates are the holidays as YYYY-MM-DD.
+	Dates []string `json:"dates,omitempty"`
+	// ConfigMapName adds the dates of the holidays key of a ConfigMap, one per
+	// line. It is read in the namespace of the EventBinding.
+	ConfigMapName string `json:"configMapName,omitempty"`
+	// Active makes the event active all day on holidays, e.g. for after-hours
+	// replies, otherwise it is inactive all day.
+	Active bool `json:"active,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -110,6 +362,12 @@ type Binding struct {
 	Name     string            `json:"name"`
 	Type     linebot.EventType `json:"type"`
 	Messages []Message         `json:"messages"`
+	Priority int32             `json:"priority,omitempty"`
+	Origin   BindingOrigin     `json:"origin,omitempty"`
+
+	Source          *EventSource   `json:"source,omitempty"`
+	MentionRequired bool           `json:"mentionRequired,omitempty"`
+	Schedule        *EventSchedule `json:"schedule,omitempty"`
 }
 
 type EventBindingSubset struct {
@@ -124,3 +382,41 @@ type EventBindingList struct {
 
 	Items []EventBinding `json:"items"`
 }
+
+// BindingOrigin is the kind of the event a subset is copied from.
+type BindingOrigin string
+
+const (
+	EventOrigin        BindingOrigin = "Event"
+	ClusterEventOrigin BindingOrigin = "ClusterEvent"
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ClusterEvent is an event shared by the bots of all the namespaces it selects.
+type ClusterEvent struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+
+	Spec ClusterEventSpec `json:"spec"`
+}
+
+type ClusterEventSpec struct {
+	// NamespaceSelector selects the namespaces of the eventbindings, all the
+	// namespaces when it is empty.
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
+	EventSpec `json:",inline"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type ClusterEventList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []ClusterEvent `json:"items"`
+}
@@ -21,6 +21,8 @@ limitations under the License.
 package v1alpha1
 
 import (
+	linebot "github.com/line/line-bot-sdk-go/linebot"
+	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
@@ -35,6 +37,16 @@ func (in *Binding) DeepCopyInto(out *Binding) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Source != nil {
+		in, out := &in.Source, &out.Source
+		*out = new(EventSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Schedule != nil {
+		in, out := &in.Schedule, &out.Schedule
+		*out = new(EventSchedule)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -76,6 +88,49 @@ func (in *Bot) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BotAutoscaling) DeepCopyInto(out *BotAutoscaling) {
+	*out = *in
+	if in.MinReplicas != nil {
+		in, out := &in.MinReplicas, &out.MinReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TargetCPUUtilizationPercentage != nil {
+		in, out := &in.TargetCPUUtilizationPercentage, &out.TargetCPUUtilizationPercentage
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BotAutoscaling.
+func (in *BotAutoscaling) DeepCopy() *BotAutoscaling {
+	if in == nil {
+		return nil
+	}
+	out := new(BotAutoscaling)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BotCondition) DeepCopyInto(out *BotCondition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BotCondition.
+func (in *BotCondition) DeepCopy() *BotCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(BotCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BotExpose) DeepCopyInto(out *BotExpose) {
 	*out = *in
@@ -97,6 +152,22 @@ func (in *BotExpose) DeepCopy() *BotExpose {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BotInsights) DeepCopyInto(out *BotInsights) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BotInsights.
+func (in *BotInsights) DeepCopy() *BotInsights {
+	if in == nil {
+		return nil
+	}
+	out := new(BotInsights)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BotList) DeepCopyInto(out *BotList) {
 	*out = *in
@@ -130,6 +201,67 @@ func (in *BotList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BotQuota) DeepCopyInto(out *BotQuota) {
+	*out = *in
+	in.PollTime.DeepCopyInto(&out.PollTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BotQuota.
+func (in *BotQuota) DeepCopy() *BotQuota {
+	if in == nil {
+		return nil
+	}
+	out := new(BotQuota)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BotRateLimit) DeepCopyInto(out *BotRateLimit) {
+	*out = *in
+	if in.PerSource != nil {
+		in, out := &in.PerSource, &out.PerSource
+		*out = new(TokenBucket)
+		**out = **in
+	}
+	if in.RuleCooldown != nil {
+		in, out := &in.RuleCooldown, &out.RuleCooldown
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BotRateLimit.
+func (in *BotRateLimit) DeepCopy() *BotRateLimit {
+	if in == nil {
+		return nil
+	}
+	out := new(BotRateLimit)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BotResources) DeepCopyInto(out *BotResources) {
+	*out = *in
+	in.Bot.DeepCopyInto(&out.Bot)
+	in.Ngrok.DeepCopyInto(&out.Ngrok)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BotResources.
+func (in *BotResources) DeepCopy() *BotResources {
+	if in == nil {
+		return nil
+	}
+	out := new(BotResources)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BotSpec) DeepCopyInto(out *BotSpec) {
 	*out = *in
@@ -139,6 +271,32 @@ func (in *BotSpec) DeepCopyInto(out *BotSpec) {
 		(*in).DeepCopyInto(*out)
 	}
 	in.Expose.DeepCopyInto(&out.Expose)
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.PodTemplate != nil {
+		in, out := &in.PodTemplate, &out.PodTemplate
+		*out = new(corev1.PodTemplateSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Autoscaling != nil {
+		in, out := &in.Autoscaling, &out.Autoscaling
+		*out = new(BotAutoscaling)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TokenSource != nil {
+		in, out := &in.TokenSource, &out.TokenSource
+		*out = new(BotTokenSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RateLimit != nil {
+		in, out := &in.RateLimit, &out.RateLimit
+		*out = new(BotRateLimit)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -156,6 +314,23 @@ func (in *BotSpec) DeepCopy() *BotSpec {
 func (in *BotStatus) DeepCopyInto(out *BotStatus) {
 	*out = *in
 	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
+	if in.Quota != nil {
+		in, out := &in.Quota, &out.Quota
+		*out = new(BotQuota)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Insights != nil {
+		in, out := &in.Insights, &out.Insights
+		*out = new(BotInsights)
+		**out = **in
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]BotCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -169,12 +344,116 @@ func (in *BotStatus) DeepCopy() *BotStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BotTokenSource) DeepCopyInto(out *BotTokenSource) {
+	*out = *in
+	if in.TokenLifetime != nil {
+		in, out := &in.TokenLifetime, &out.TokenLifetime
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BotTokenSource.
+func (in *BotTokenSource) DeepCopy() *BotTokenSource {
+	if in == nil {
+		return nil
+	}
+	out := new(BotTokenSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterEvent) DeepCopyInto(out *ClusterEvent) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterEvent.
+func (in *ClusterEvent) DeepCopy() *ClusterEvent {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterEvent)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterEvent) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterEventList) DeepCopyInto(out *ClusterEventList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterEvent, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterEventList.
+func (in *ClusterEventList) DeepCopy() *ClusterEventList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterEventList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterEventList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterEventSpec) DeepCopyInto(out *ClusterEventSpec) {
+	*out = *in
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	in.EventSpec.DeepCopyInto(&out.EventSpec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterEventSpec.
+func (in *ClusterEventSpec) DeepCopy() *ClusterEventSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterEventSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Event) DeepCopyInto(out *Event) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -279,6 +558,22 @@ func (in *EventBindingSubset) DeepCopy() *EventBindingSubset {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EventConflict) DeepCopyInto(out *EventConflict) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EventConflict.
+func (in *EventConflict) DeepCopy() *EventConflict {
+	if in == nil {
+		return nil
+	}
+	out := new(EventConflict)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EventList) DeepCopyInto(out *EventList) {
 	*out = *in
@@ -312,6 +607,63 @@ func (in *EventList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EventSchedule) DeepCopyInto(out *EventSchedule) {
+	*out = *in
+	if in.Windows != nil {
+		in, out := &in.Windows, &out.Windows
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Holidays != nil {
+		in, out := &in.Holidays, &out.Holidays
+		*out = new(ScheduleHolidays)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EventSchedule.
+func (in *EventSchedule) DeepCopy() *EventSchedule {
+	if in == nil {
+		return nil
+	}
+	out := new(EventSchedule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EventSource) DeepCopyInto(out *EventSource) {
+	*out = *in
+	if in.Types != nil {
+		in, out := &in.Types, &out.Types
+		*out = make([]linebot.EventSourceType, len(*in))
+		copy(*out, *in)
+	}
+	if in.Allow != nil {
+		in, out := &in.Allow, &out.Allow
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Deny != nil {
+		in, out := &in.Deny, &out.Deny
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EventSource.
+func (in *EventSource) DeepCopy() *EventSource {
+	if in == nil {
+		return nil
+	}
+	out := new(EventSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EventSpec) DeepCopyInto(out *EventSpec) {
 	*out = *in
@@ -327,6 +679,16 @@ func (in *EventSpec) DeepCopyInto(out *EventSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Source != nil {
+		in, out := &in.Source, &out.Source
+		*out = new(EventSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Schedule != nil {
+		in, out := &in.Schedule, &out.Schedule
+		*out = new(EventSchedule)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -340,6 +702,37 @@ func (in *EventSpec) DeepCopy() *EventSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EventStatus) DeepCopyInto(out *EventStatus) {
+	*out = *in
+	if in.Bindings != nil {
+		in, out := &in.Bindings, &out.Bindings
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Errors != nil {
+		in, out := &in.Errors, &out.Errors
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conflicts != nil {
+		in, out := &in.Conflicts, &out.Conflicts
+		*out = make([]EventConflict, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EventStatus.
+func (in *EventStatus) DeepCopy() *EventStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(EventStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Message) DeepCopyInto(out *Message) {
 	*out = *in
@@ -348,6 +741,26 @@ func (in *Message) DeepCopyInto(out *Message) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Patterns != nil {
+		in, out := &in.Patterns, &out.Patterns
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Replies != nil {
+		in, out := &in.Replies, &out.Replies
+		*out = make([]ReplyVariant, len(*in))
+		copy(*out, *in)
+	}
+	if in.Selection != nil {
+		in, out := &in.Selection, &out.Selection
+		*out = new(ReplySelection)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.I18n != nil {
+		in, out := &in.I18n, &out.I18n
+		*out = new(MessageI18n)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -360,3 +773,101 @@ func (in *Message) DeepCopy() *Message {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MessageI18n) DeepCopyInto(out *MessageI18n) {
+	*out = *in
+	if in.Replies != nil {
+		in, out := &in.Replies, &out.Replies
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MessageI18n.
+func (in *MessageI18n) DeepCopy() *MessageI18n {
+	if in == nil {
+		return nil
+	}
+	out := new(MessageI18n)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplySelection) DeepCopyInto(out *ReplySelection) {
+	*out = *in
+	if in.Seed != nil {
+		in, out := &in.Seed, &out.Seed
+		*out = new(int64)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplySelection.
+func (in *ReplySelection) DeepCopy() *ReplySelection {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplySelection)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplyVariant) DeepCopyInto(out *ReplyVariant) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplyVariant.
+func (in *ReplyVariant) DeepCopy() *ReplyVariant {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplyVariant)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduleHolidays) DeepCopyInto(out *ScheduleHolidays) {
+	*out = *in
+	if in.Dates != nil {
+		in, out := &in.Dates, &out.Dates
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduleHolidays.
+func (in *ScheduleHolidays) DeepCopy() *ScheduleHolidays {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduleHolidays)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TokenBucket) DeepCopyInto(out *TokenBucket) {
+	*out = *in
+	out.RefillInterval = in.RefillInterval
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TokenBucket.
+func (in *TokenBucket) DeepCopy() *TokenBucket {
+	if in == nil {
+		return nil
+	}
+	out := new(TokenBucket)
+	in.DeepCopyInto(out)
+	return out
+}
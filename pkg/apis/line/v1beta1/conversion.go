@@ -0,0 +1,545 @@
+package v1beta1
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/kairen/line-bot-operator/pkg/apis/line/v1alpha1"
+	"github.com/line/line-bot-sdk-go/linebot"
+)
+
+// RepliesAnnotation keeps the typed replies that cannot be represented by the v1alpha1
+// reply string, so that a v1beta1 object survives a round trip through v1alpha1.
+const RepliesAnnotation = "line.you/v1beta1-replies"
+
+func Convert_v1alpha1_Bot_To_v1beta1_Bot(in *v1alpha1.Bot, out *Bot) error {
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec.Selector = in.Spec.Selector.DeepCopy()
+	out.Spec.ChannelSecretName = in.Spec.ChannelSecretName
+	out.Spec.Expose.Type = BotExposeType(in.Spec.Expose.Type)
+	out.Spec.Expose.DomainName = in.Spec.Expose.DomainName
+	out.Spec.Expose.LoadBalanceIPs = append([]string(nil), in.Spec.Expose.LoadBalanceIPs...)
+	out.Spec.Expose.NgrokToken = in.Spec.Expose.NgrokToken
+	out.Spec.Version = in.Spec.Version
+	out.Spec.LogLevel = in.Spec.LogLevel
+	in.Spec.Resources.Bot.DeepCopyInto(&out.Spec.Resources.Bot)
+	in.Spec.Resources.Ngrok.DeepCopyInto(&out.Spec.Resources.Ngrok)
+	out.Spec.PodTemplate = in.Spec.PodTemplate.DeepCopy()
+	out.Spec.Replicas = copyInt32(in.Spec.Replicas)
+	if in.Spec.Autoscaling != nil {
+		out.Spec.Autoscaling = &BotAutoscaling{
+			MinReplicas:                    copyInt32(in.Spec.Autoscaling.MinReplicas),
+			MaxReplicas:                    in.Spec.Autoscaling.MaxReplicas,
+			TargetCPUUtilizationPercentage: copyInt32(in.Spec.Autoscaling.TargetCPUUtilizationPercentage),
+		}
+	}
+	if in.Spec.TokenSource != nil {
+		out.Spec.TokenSource = &BotTokenSource{
+			Type:                 BotTokenSourceType(in.Spec.TokenSource.Type),
+			ChannelID:            in.Spec.TokenSource.ChannelID,
+			PrivateKeySecretName: in.Spec.TokenSource.PrivateKeySecretName,
+		}
+		if in.Spec.TokenSource.TokenLifetime != nil {
+			lifetime := *in.Spec.TokenSource.TokenLifetime
+			out.Spec.TokenSource.TokenLifetime = &lifetime
+		}
+	}
+	out.Spec.RateLimit = convertRateLimitToV1beta1(in.Spec.RateLimit)
+	out.Status.Phase = BotPhase(in.Status.Phase)
+	out.Status.Reason = in.Status.Reason
+	out.Status.LastUpdateTime = in.Status.LastUpdateTime
+	if in.Status.Quota != nil {
+		quota := BotQuota(*in.Status.Quota)
+		out.Status.Quota = &quota
+	}
+	if in.Status.Insights != nil {
+		insights := BotInsights(*in.Status.Insights)
+		out.Status.Insights = &insights
+	}
+	out.Status.Conditions = nil
+	for _, condition := range in.Status.Conditions {
+		out.Status.Conditions = append(out.Status.Conditions, BotCondition{
+			Type:               BotConditionType(condition.Type),
+			Status:             condition.Status,
+			LastTransitionTime: condition.LastTransitionTime,
+			Reason:             condition.Reason,
+			Message:            condition.Message,
+		})
+	}
+	return nil
+}
+
+func Convert_v1beta1_Bot_To_v1alpha1_Bot(in *Bot, out *v1alpha1.Bot) error {
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec.Selector = in.Spec.Selector.DeepCopy()
+	out.Spec.ChannelSecretName = in.Spec.ChannelSecretName
+	out.Spec.Expose.Type = v1alpha1.BotExposeType(in.Spec.Expose.Type)
+	out.Spec.Expose.DomainName = in.Spec.Expose.DomainName
+	out.Spec.Expose.LoadBalanceIPs = append([]string(nil), in.Spec.Expose.LoadBalanceIPs...)
+	out.Spec.Expose.NgrokToken = in.Spec.Expose.NgrokToken
+	out.Spec.Version = in.Spec.Version
+	out.Spec.LogLevel = in.Spec.LogLevel
+	in.Spec.Resources.Bot.DeepCopyInto(&out.Spec.Resources.Bot)
+	in.Spec.Resources.Ngrok.DeepCopyInto(&out.Spec.Resources.Ngrok)
+	out.Spec.PodTemplate = in.Spec.PodTemplate.DeepCopy()
+	out.Spec.Replicas = copyInt32(in.Spec.Replicas)
+	if in.Spec.Autoscaling != nil {
+		out.Spec.Autoscaling = &v1alpha1.BotAutoscaling{
+			MinReplicas:                    copyInt32(in.Spec.Autoscaling.MinReplicas),
+			MaxReplicas:                    in.Spec.Autoscaling.MaxReplicas,
+			TargetCPUUtilizationPercentage: copyInt32(in.Spec.Autoscaling.TargetCPUUtilizationPercentage),
+		}
+	}
+	if in.Spec.TokenSource != nil {
+		out.Spec.TokenSource = &v1alpha1.BotTokenSource{
+			Type:                 v1alpha1.BotTokenSourceType(in.Spec.TokenSource.Type),
+			ChannelID:            in.Spec.TokenSource.ChannelID,
+			PrivateKeySecretName: in.Spec.TokenSource.PrivateKeySecretName,
+		}
+		if in.Spec.TokenSource.TokenLifetime != nil {
+			lifetime := *in.Spec.TokenSource.TokenLifetime
+			out.Spec.TokenSource.TokenLifetime = &lifetime
+		}
+	}
+	out.Spec.RateLimit = convertRateLimitToV1alpha1(in.Spec.RateLimit)
+	out.Status.Phase = v1alpha1.BotPhase(in.Status.Phase)
+	out.Status.Reason = in.Status.Reason
+	out.Status.LastUpdateTime = in.Status.LastUpdateTime
+	if in.Status.Quota != nil {
+		quota := v1alpha1.BotQuota(*in.Status.Quota)
+		out.Status.Quota = &quota
+	}
+	if in.Status.Insights != nil {
+		insights := v1alpha1.BotInsights(*in.Status.Insights)
+		out.Status.Insights = &insights
+	}
+	out.Status.Conditions = nil
+	for _, condition := range in.Status.Conditions {
+		out.Status.Conditions = append(out.Status.Conditions, v1alpha1.BotCondition{
+			Type:               v1alpha1.BotConditionType(condition.Type),
+			Status:             condition.Status,
+			LastTransitionTime: condition.LastTransitionTime,
+			Reason:             condition.Reason,
+			Message:            condition.Message,
+		})
+	}
+	return nil
+}
+
+func convertRateLimitToV1beta1(in *v1alpha1.BotRateLimit) *BotRateLimit {
+	if in == nil {
+		return nil
+	}
+	out := &BotRateLimit{
+		RepliesPerSecond: in.RepliesPerSecond,
+		Burst:            in.Burst,
+		Action:           RateLimitAction(in.Action),
+		Notice:           in.Notice,
+	}
+	if in.PerSource != nil {
+		out.PerSource = &TokenBucket{Capacity: in.PerSource.Capacity, RefillInterval: in.PerSource.RefillInterval}
+	}
+	if in.RuleCooldown != nil {
+		cooldown := *in.RuleCooldown
+		out.RuleCooldown = &cooldown
+	}
+	return out
+}
+
+func convertRateLimitToV1alpha1(in *BotRateLimit) *v1alpha1.BotRateLimit {
+	if in == nil {
+		return nil
+	}
+	out := &v1alpha1.BotRateLimit{
+		RepliesPerSecond: in.RepliesPerSecond,
+		Burst:            in.Burst,
+		Action:           v1alpha1.RateLimitAction(in.Action),
+		Notice:           in.Notice,
+	}
+	if in.PerSource != nil {
+		out.PerSource = &v1alpha1.TokenBucket{Capacity: in.PerSource.Capacity, RefillInterval: in.PerSource.RefillInterval}
+	}
+	if in.RuleCooldown != nil {
+		cooldown := *in.RuleCooldown
+		out.RuleCooldown = &cooldown
+	}
+	return out
+}
+
+func Convert_v1alpha1_Event_To_v1beta1_Event(in *v1alpha1.Event, out *Event) error {
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	stored := popStoredReplies(&out.ObjectMeta.Annotations)
+	out.Spec.Selector = in.Spec.Selector.DeepCopy()
+	out.Spec.Type = in.Spec.Type
+	out.Spec.Messages = convertMessagesToV1beta1("", in.Spec.Messages, stored)
+	out.Spec.Priority = in.Spec.Priority
+	out.Spec.MentionRequired = in.Spec.MentionRequired
+	out.Spec.Source = convertSourceToV1beta1(in.Spec.Source)
+	out.Spec.Schedule = convertScheduleToV1beta1(in.Spec.Schedule)
+	out.Status.ObservedGeneration = in.Status.ObservedGeneration
+	out.Status.Bindings = append([]string(nil), in.Status.Bindings...)
+	out.Status.Errors = append([]string(nil), in.Status.Errors...)
+	out.Status.Active = in.Status.Active
+	out.Status.Conflicts = nil
+	for _, conflict := range in.Status.Conflicts {
+		out.Status.Conflicts = append(out.Status.Conflicts, EventConflict(conflict))
+	}
+	return nil
+}
+
+func Convert_v1beta1_Event_To_v1alpha1_Event(in *Event, out *v1alpha1.Event) error {
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	stored := map[string][]ReplyMessage{}
+	out.Spec.Selector = in.Spec.Selector.DeepCopy()
+	out.Spec.Type = in.Spec.Type
+	out.Spec.Messages = convertMessagesToV1alpha1("", in.Spec.Messages, stored)
+	out.Spec.Priority = in.Spec.Priority
+	out.Spec.MentionRequired = in.Spec.MentionRequired
+	out.Spec.Source = convertSourceToV1alpha1(in.Spec.Source)
+	out.Spec.Schedule = convertScheduleToV1alpha1(in.Spec.Schedule)
+	out.Status.ObservedGeneration = in.Status.ObservedGeneration
+	out.Status.Bindings = append([]string(nil), in.Status.Bindings...)
+	out.Status.Errors = append([]string(nil), in.Status.Errors...)
+	out.Status.Active = in.Status.Active
+	out.Status.Conflicts = nil
+	for _, conflict := range in.Status.Conflicts {
+		out.Status.Conflicts = append(out.Status.Conflicts, v1alpha1.EventConflict(conflict))
+	}
+	return pushStoredReplies(&out.ObjectMeta.Annotations, stored)
+}
+
+func Convert_v1alpha1_EventBinding_To_v1beta1_EventBinding(in *v1alpha1.EventBinding, out *EventBinding) error {
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	stored := popStoredReplies(&out.ObjectMeta.Annotations)
+	out.Subsets = nil
+	for _, subset := range in.Subsets {
+		out.Subsets = append(out.Subsets, EventBindingSubset{
+			Name:     subset.Binding.Name,
+			Type:     subset.Binding.Type,
+			Messages: convertMessagesToV1beta1(subset.Binding.Name, subset.Binding.Messages, stored),
+			Priority: subset.Binding.Priority,
+			Origin:   BindingOrigin(subset.Binding.Origin),
+
+			Source:          convertSourceToV1beta1(subset.Binding.Source),
+			MentionRequired: subset.Binding.MentionRequired,
+			Schedule:        convertScheduleToV1beta1(subset.Binding.Schedule),
+		})
+	}
+	return nil
+}
+
+func Convert_v1beta1_EventBinding_To_v1alpha1_EventBinding(in *EventBinding, out *v1alpha1.EventBinding) error {
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	stored := map[string][]ReplyMessage{}
+	out.Subsets = nil
+	for _, subset := range in.Subsets {
+		out.Subsets = append(out.Subsets, v1alpha1.EventBindingSubset{
+			Binding: v1alpha1.Binding{
+				Name:     subset.Name,
+				Type:     subset.Type,
+				Messages: convertMessagesToV1alpha1(subset.Name, subset.Messages, stored),
+				Priority: subset.Priority,
+				Origin:   v1alpha1.BindingOrigin(subset.Origin),
+
+				Source:          convertSourceToV1alpha1(subset.Source),
+				MentionRequired: subset.MentionRequired,
+				Schedule:        convertScheduleToV1alpha1(subset.Schedule),
+			},
+		})
+	}
+	return pushStoredReplies(&out.ObjectMeta.Annotations, stored)
+}
+
+func Convert_v1alpha1_ClusterEvent_To_v1beta1_ClusterEvent(in *v1alpha1.ClusterEvent, out *ClusterEvent) error {
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	stored := popStoredReplies(&out.ObjectMeta.Annotations)
+	out.Spec.NamespaceSelector = in.Spec.NamespaceSelector.DeepCopy()
+	out.Spec.Selector = in.Spec.Selector.DeepCopy()
+	out.Spec.Type = in.Spec.Type
+	out.Spec.Messages = convertMessagesToV1beta1("", in.Spec.Messages, stored)
+	out.Spec.Priority = in.Spec.Priority
+	out.Spec.MentionRequired = in.Spec.MentionRequired
+	out.Spec.Source = convertSourceToV1beta1(in.Spec.Source)
+	out.Spec.Schedule = convertScheduleToV1beta1(in.Spec.Schedule)
+	return nil
+}
+
+func Convert_v1beta1_ClusterEvent_To_v1alpha1_ClusterEvent(in *ClusterEvent, out *v1alpha1.ClusterEvent) error {
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	stored := map[string][]ReplyMessage{}
+	out.Spec.NamespaceSelector = in.Spec.NamespaceSelector.DeepCopy()
+	out.Spec.Selector = in.Spec.Selector.DeepCopy()
+	out.Spec.Type = in.Spec.Type
+	out.Spec.Messages = convertMessagesToV1alpha1("", in.Spec.Messages, stored)
+	out.Spec.Priority = in.Spec.Priority
+	out.Spec.MentionRequired = in.Spec.MentionRequired
+	out.Spec.Source = convertSourceToV1alpha1(in.Spec.Source)
+	out.Spec.Schedule = convertScheduleToV1alpha1(in.Spec.Schedule)
+	return pushStoredReplies(&out.ObjectMeta.Annotations, stored)
+}
+
+func convertMessagesToV1beta1(prefix string, in []v1alpha1.Message, stored map[string][]ReplyMessage) []Message {
+	if in == nil {
+		return nil
+	}
+
+	out := make([]Message, len(in))
+	for i, msg := range in {
+		out[i] = Message{
+			Type:     msg.Type,
+			Keywords: append([]string(nil), msg.Keywords...),
+			Patterns: append([]string(nil), msg.Patterns...),
+		}
+
+		out[i].Reply = restoreReplies(stored, storedRepliesKey(prefix, i), msg.Reply)
+		for j, variant := range msg.Replies {
+			out[i].Replies = append(out[i].Replies, ReplyVariant{
+				Weight: variant.Weight,
+				Reply:  restoreReplies(stored, storedVariantKey(prefix, i, j), variant.Reply),
+			})
+		}
+		if msg.Selection != nil {
+			out[i].Selection = &ReplySelection{
+				Strategy: SelectionStrategy(msg.Selection.Strategy),
+				Window:   msg.Selection.Window,
+				Seed:     copyInt64(msg.Selection.Seed),
+			}
+		}
+		if msg.I18n != nil {
+			out[i].I18n = &MessageI18n{
+				Fallback:      msg.I18n.Fallback,
+				ConfigMapName: msg.I18n.ConfigMapName,
+				Key:           msg.I18n.Key,
+			}
+			for locale, reply := range msg.I18n.Replies {
+				if out[i].I18n.Replies == nil {
+					out[i].I18n.Replies = map[string][]ReplyMessage{}
+				}
+				out[i].I18n.Replies[locale] = restoreReplies(stored, storedLocaleKey(prefix, i, locale), reply)
+			}
+		}
+	}
+	return out
+}
+
+// restoreReplies returns the stored typed replies unless the reply string was
+// changed in v1alpha1.
+func restoreReplies(stored map[string][]ReplyMessage, key, reply string) []ReplyMessage {
+	if replies, ok := stored[key]; ok && flattenReplies(replies) == reply {
+		return replies
+	}
+	if reply != "" {
+		return []ReplyMessage{{Type: linebot.MessageTypeText, Text: reply}}
+	}
+	return nil
+}
+
+func convertMessagesToV1alpha1(prefix string, in []Message, stored map[string][]ReplyMessage) []v1alpha1.Message {
+	if in == nil {
+		return nil
+	}
+
+	out := make([]v1alpha1.Message, len(in))
+	for i, msg := range in {
+		out[i] = v1alpha1.Message{
+			Type:     msg.Type,
+			Keywords: append([]string(nil), msg.Keywords...),
+			Patterns: append([]string(nil), msg.Patterns...),
+			Reply:    flattenReplies(msg.Reply),
+		}
+
+		if !isPlainTextReply(msg.Reply) {
+			stored[storedRepliesKey(prefix, i)] = msg.Reply
+		}
+
+		for j, variant := range msg.Replies {
+			out[i].Replies = append(out[i].Replies, v1alpha1.ReplyVariant{
+				Weight: variant.Weight,
+				Reply:  flattenReplies(variant.Reply),
+			})
+			if !isPlainTextReply(variant.Reply) {
+				stored[storedVariantKey(prefix, i, j)] = variant.Reply
+			}
+		}
+		if msg.Selection != nil {
+			out[i].Selection = &v1alpha1.ReplySelection{
+				Strategy: v1alpha1.SelectionStrategy(msg.Selection.Strategy),
+				Window:   msg.Selection.Window,
+				Seed:     copyInt64(msg.Selection.Seed),
+			}
+		}
+		if msg.I18n != nil {
+			out[i].I18n = &v1alpha1.MessageI18n{
+				Fallback:      msg.I18n.Fallback,
+				ConfigMapName: msg.I18n.ConfigMapName,
+				Key:           msg.I18n.Key,
+			}
+			for locale, reply := range msg.I18n.Replies {
+				if out[i].I18n.Replies == nil {
+					out[i].I18n.Replies = map[string]string{}
+				}
+				out[i].I18n.Replies[locale] = flattenReplies(reply)
+				if !isPlainTextReply(reply) {
+					stored[storedLocaleKey(prefix, i, locale)] = reply
+				}
+			}
+		}
+	}
+	return out
+}
+
+func convertSourceToV1beta1(in *v1alpha1.EventSource) *EventSource {
+	if in == nil {
+		return nil
+	}
+	return &EventSource{
+		Types:         append([]linebot.EventSourceType(nil), in.Types...),
+		Allow:         append([]string(nil), in.Allow...),
+		Deny:          append([]string(nil), in.Deny...),
+		ConfigMapName: in.ConfigMapName,
+	}
+}
+
+func convertSourceToV1alpha1(in *EventSource) *v1alpha1.EventSource {
+	if in == nil {
+		return nil
+	}
+	return &v1alpha1.EventSource{
+		Types:         append([]linebot.EventSourceType(nil), in.Types...),
+		Allow:         append([]string(nil), in.Allow...),
+		Deny:          append([]string(nil), in.Deny...),
+		ConfigMapName: in.ConfigMapName,
+	}
+}
+
+func convertScheduleToV1beta1(in *v1alpha1.EventSchedule) *EventSchedule {
+	if in == nil {
+		return nil
+	}
+	out := &EventSchedule{
+		TimeZone:  in.TimeZone,
+		Windows:   append([]string(nil), in.Windows...),
+		StartDate: in.StartDate,
+		EndDate:   in.EndDate,
+	}
+	if in.Holidays != nil {
+		out.Holidays = &ScheduleHolidays{
+			Dates:         append([]string(nil), in.Holidays.Dates...),
+			ConfigMapName: in.Holidays.ConfigMapName,
+			Active:        in.Holidays.Active,
+		}
+	}
+	return out
+}
+
+func convertScheduleToV1alpha1(in *EventSchedule) *v1alpha1.EventSchedule {
+	if in == nil {
+		return nil
+	}
+	out := &v1alpha1.EventSchedule{
+		TimeZone:  in.TimeZone,
+		Windows:   append([]string(nil), in.Windows...),
+		StartDate: in.StartDate,
+		EndDate:   in.EndDate,
+	}
+	if in.Holidays != nil {
+		out.Holidays = &v1alpha1.ScheduleHolidays{
+			Dates:         append([]string(nil), in.Holidays.Dates...),
+			ConfigMapName: in.Holidays.ConfigMapName,
+			Active:        in.Holidays.Active,
+		}
+	}
+	return out
+}
+
+func copyInt32(in *int32) *int32 {
+	if in == nil {
+		return nil
+	}
+	out := *in
+	return &out
+}
+
+func copyInt64(in *int64) *int64 {
+	if in == nil {
+		return nil
+	}
+	out := *in
+	return &out
+}
+
+// flattenReplies joins the text replies, which is the closest v1alpha1 reply string.
+func flattenReplies(replies []ReplyMessage) string {
+	texts := []string{}
+	for _, reply := range replies {
+		if reply.Type == linebot.MessageTypeText {
+			texts = append(texts, reply.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+func isPlainTextReply(replies []ReplyMessage) bool {
+	switch len(replies) {
+	case 0:
+		return true
+	case 1:
+		return replies[0] == ReplyMessage{Type: linebot.MessageTypeText, Text: replies[0].Text}
+	}
+	return false
+}
+
+func storedRepliesKey(prefix string, index int) string {
+	if prefix == "" {
+		return fmt.Sprintf("%d", index)
+	}
+	return fmt.Sprintf("%s/%d", prefix, index)
+}
+
+func storedVariantKey(prefix string, index, variant int) string {
+	return fmt.Sprintf("%s/replies/%d", storedRepliesKey(prefix, index), variant)
+}
+
+func storedLocaleKey(prefix string, index int, locale string) string {
+	return fmt.Sprintf("%s/i18n/%s", storedRepliesKey(prefix, index), locale)
+}
+
+func popStoredReplies(annotations *map[string]string) map[string][]ReplyMessage {
+	stored := map[string][]ReplyMessage{}
+	if value, ok := (*annotations)[RepliesAnnotation]; ok {
+		if err := json.Unmarshal([]byte(value), &stored); err != nil {
+			stored = map[string][]ReplyMessage{}
+		}
+		delete(*annotations, RepliesAnnotation)
+		if len(*annotations) == 0 {
+			*annotations = nil
+		}
+	}
+	return stored
+}
+
+func pushStoredReplies(annotations *map[string]string, stored map[string][]ReplyMessage) error {
+	if len(stored) == 0 {
+		return nil
+	}
+
+	value, err := json.Marshal(stored)
+	if err != nil {
+		return err
+	}
+
+	if *annotations == nil {
+		*annotations = map[string]string{}
+	}
+	(*annotations)[RepliesAnnotation] = string(value)
+	return nil
+}
@@ -0,0 +1,312 @@
+package v1beta1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kairen/line-bot-operator/pkg/apis/line/v1alpha1"
+	"github.com/line/line-bot-sdk-go/linebot"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/diff"
+)
+
+var (
+	selector = &metav1.LabelSelector{MatchLabels: map[string]string{"hunter": "monster"}}
+	seed     = int64(7)
+)
+
+func text(s string) ReplyMessage {
+	return ReplyMessage{Type: linebot.MessageTypeText, Text: s}
+}
+
+func sticker(packageID, stickerID string) ReplyMessage {
+	return ReplyMessage{Type: linebot.MessageTypeSticker, PackageID: packageID, StickerID: stickerID}
+}
+
+// v1beta1Messages can't be represented by the v1alpha1 reply strings.
+func v1beta1Messages() []Message {
+	return []Message{
+		{
+			Type:     linebot.MessageTypeText,
+			Keywords: []string{"hello"},
+			Reply:    []ReplyMessage{text("Hello~"), sticker("11537", "52002734"), text("Meow~")},
+		},
+		{
+			Type:     linebot.MessageTypeText,
+			Patterns: []string{"^bye"},
+			Replies: []ReplyVariant{
+				{Weight: 3, Reply: []ReplyMessage{text("Bye~")}},
+				{Reply: []ReplyMessage{{
+					Type:               linebot.MessageTypeImage,
+					OriginalContentURL: "https://example.com/bye.png",
+					PreviewImageURL:    "https://example.com/bye-preview.png",
+				}}},
+			},
+			Selection: &ReplySelection{Strategy: SelectionNoRepeat, Window: 1, Seed: &seed},
+			I18n: &MessageI18n{
+				Fallback: "en",
+				Replies: map[string][]ReplyMessage{
+					"en":    {text("Bye~")},
+					"zh-TW": {text("掰掰"), sticker("11537", "52002735")},
+				},
+			},
+		},
+	}
+}
+
+func v1alpha1Messages() []v1alpha1.Message {
+	return []v1alpha1.Message{
+		{Type: linebot.MessageTypeText, Keywords: []string{"hello"}, Reply: "Hello~\nMeow~"},
+		{
+			Type:    linebot.MessageTypeText,
+			Replies: []v1alpha1.ReplyVariant{{Weight: 2, Reply: "Bye~"}, {Reply: "See you~"}},
+			Selection: &v1alpha1.ReplySelection{
+				Strategy: v1alpha1.SelectionRoundRobin,
+			},
+			I18n: &v1alpha1.MessageI18n{
+				Fallback:      "en",
+				ConfigMapName: "replies",
+				Key:           "bye",
+				Replies:       map[string]string{"ja": "またね"},
+			},
+		},
+	}
+}
+
+func v1alpha1Schedule() *v1alpha1.EventSchedule {
+	return &v1alpha1.EventSchedule{
+		TimeZone:  "Asia/Taipei",
+		Windows:   []string{"* 9-18 * * 1-5"},
+		StartDate: "2019-04-01",
+		Holidays:  &v1alpha1.ScheduleHolidays{Dates: []string{"2019-04-05"}, ConfigMapName: "holidays"},
+	}
+}
+
+func checkEqual(t *testing.T, name string, got, want interface{}) {
+	if !equality.Semantic.DeepEqual(got, want) {
+		t.Errorf("%s: round trip differs: %s", name, diff.ObjectReflectDiff(want, got))
+	}
+}
+
+func TestEventRoundTrip(t *testing.T) {
+	in := &Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "hello",
+			Namespace:   "default",
+			Annotations: map[string]string{"owner": "mhw"},
+		},
+		Spec: EventSpec{
+			Selector:        selector,
+			Type:            linebot.EventTypeMessage,
+			Messages:        v1beta1Messages(),
+			Priority:        10,
+			MentionRequired: true,
+			Source:          &EventSource{Types: []linebot.EventSourceType{linebot.EventSourceTypeGroup}, Deny: []string{"U1"}},
+		},
+		Status: EventStatus{
+			Bindings:  []string{"test-bot"},
+			Conflicts: []EventConflict{{Keyword: "hello", Event: "hi", Bot: "test-bot", Tie: true}},
+			Active:    true,
+		},
+	}
+
+	alpha := &v1alpha1.Event{}
+	if err := Convert_v1beta1_Event_To_v1alpha1_Event(in, alpha); err != nil {
+		t.Fatal(err)
+	}
+	if got := alpha.Spec.Messages[0].Reply; got != "Hello~\nMeow~" {
+		t.Errorf("got v1alpha1 reply %q, want the texts flattened", got)
+	}
+	if alpha.Annotations[RepliesAnnotation] == "" || alpha.Annotations["owner"] != "mhw" {
+		t.Errorf("got annotations %v, want the stored replies and the owner", alpha.Annotations)
+	}
+
+	out := &Event{}
+	if err := Convert_v1alpha1_Event_To_v1beta1_Event(alpha, out); err != nil {
+		t.Fatal(err)
+	}
+	checkEqual(t, "v1beta1", out, in)
+
+	// A reply edited in v1alpha1 replaces the stored one.
+	alpha.Spec.Messages[0].Reply = "Hi~"
+	edited := &Event{}
+	if err := Convert_v1alpha1_Event_To_v1beta1_Event(alpha, edited); err != nil {
+		t.Fatal(err)
+	}
+	checkEqual(t, "edited", edited.Spec.Messages[0].Reply, []ReplyMessage{text("Hi~")})
+	checkEqual(t, "edited variants", edited.Spec.Messages[1], in.Spec.Messages[1])
+}
+
+func TestV1alpha1EventRoundTrip(t *testing.T) {
+	in := &v1alpha1.Event{
+		ObjectMeta: metav1.ObjectMeta{Name: "hello", Namespace: "default"},
+		Spec: v1alpha1.EventSpec{
+			Selector: selector,
+			Type:     linebot.EventTypeMessage,
+			Messages: v1alpha1Messages(),
+			Schedule: v1alpha1Schedule(),
+		},
+		Status: v1alpha1.EventStatus{ObservedGeneration: 2, Errors: []string{"conflict"}},
+	}
+
+	beta := &Event{}
+	if err := Convert_v1alpha1_Event_To_v1beta1_Event(in, beta); err != nil {
+		t.Fatal(err)
+	}
+	checkEqual(t, "v1beta1 reply", beta.Spec.Messages[0].Reply, []ReplyMessage{text("Hello~\nMeow~")})
+
+	out := &v1alpha1.Event{}
+	if err := Convert_v1beta1_Event_To_v1alpha1_Event(beta, out); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := out.Annotations[RepliesAnnotation]; ok {
+		t.Errorf("got annotations %v, want no stored replies for plain texts", out.Annotations)
+	}
+	checkEqual(t, "v1alpha1", out, in)
+}
+
+func TestEventBindingRoundTrip(t *testing.T) {
+	in := &EventBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-bot", Namespace: "default", Labels: selector.MatchLabels},
+		Subsets: []EventBindingSubset{
+			{
+				Name:     "hello",
+				Type:     linebot.EventTypeMessage,
+				Messages: v1beta1Messages(),
+				Priority: 10,
+				Origin:   EventOrigin,
+				Source:   &EventSource{Allow: []string{"C1"}, ConfigMapName: "chats"},
+			},
+			{
+				Name:            "welcome",
+				Type:            linebot.EventTypeFollow,
+				Messages:        []Message{{Type: linebot.MessageTypeText, Reply: []ReplyMessage{sticker("11537", "52002734")}}},
+				Origin:          ClusterEventOrigin,
+				MentionRequired: true,
+				Schedule:        &EventSchedule{TimeZone: "UTC", EndDate: "2019-12-31"},
+			},
+		},
+	}
+
+	alpha := &v1alpha1.EventBinding{}
+	if err := Convert_v1beta1_EventBinding_To_v1alpha1_EventBinding(in, alpha); err != nil {
+		t.Fatal(err)
+	}
+	if len(alpha.Subsets) != 2 || alpha.Subsets[1].Binding.Name != "welcome" ||
+		alpha.Subsets[1].Binding.Origin != v1alpha1.ClusterEventOrigin {
+		t.Fatalf("got v1alpha1 subsets %+v, want the bindings of hello and welcome", alpha.Subsets)
+	}
+
+	out := &EventBinding{}
+	if err := Convert_v1alpha1_EventBinding_To_v1beta1_EventBinding(alpha, out); err != nil {
+		t.Fatal(err)
+	}
+	checkEqual(t, "v1beta1", out, in)
+}
+
+func TestV1alpha1EventBindingRoundTrip(t *testing.T) {
+	in := &v1alpha1.EventBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-bot", Namespace: "default"},
+		Subsets: []v1alpha1.EventBindingSubset{{
+			Binding: v1alpha1.Binding{
+				Name:     "hello",
+				Type:     linebot.EventTypeMessage,
+				Messages: v1alpha1Messages(),
+				Origin:   v1alpha1.EventOrigin,
+				Schedule: v1alpha1Schedule(),
+			},
+		}},
+	}
+
+	beta := &EventBinding{}
+	if err := Convert_v1alpha1_EventBinding_To_v1beta1_EventBinding(in, beta); err != nil {
+		t.Fatal(err)
+	}
+	out := &v1alpha1.EventBinding{}
+	if err := Convert_v1beta1_EventBinding_To_v1alpha1_EventBinding(beta, out); err != nil {
+		t.Fatal(err)
+	}
+	checkEqual(t, "v1alpha1", out, in)
+}
+
+func TestClusterEventRoundTrip(t *testing.T) {
+	in := &ClusterEvent{
+		ObjectMeta: metav1.ObjectMeta{Name: "welcome", Annotations: map[string]string{"owner": "mhw"}},
+		Spec: ClusterEventSpec{
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "bots"}},
+			EventSpec: EventSpec{
+				Selector: &metav1.LabelSelector{},
+				Type:     linebot.EventTypeMessage,
+				Messages: v1beta1Messages(),
+			},
+		},
+	}
+
+	alpha := &v1alpha1.ClusterEvent{}
+	if err := Convert_v1beta1_ClusterEvent_To_v1alpha1_ClusterEvent(in, alpha); err != nil {
+		t.Fatal(err)
+	}
+	out := &ClusterEvent{}
+	if err := Convert_v1alpha1_ClusterEvent_To_v1beta1_ClusterEvent(alpha, out); err != nil {
+		t.Fatal(err)
+	}
+	checkEqual(t, "v1beta1", out, in)
+}
+
+func TestBotRoundTrip(t *testing.T) {
+	replicas, minReplicas := int32(2), int32(1)
+	in := &v1alpha1.Bot{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-bot", Namespace: "default"},
+		Spec: v1alpha1.BotSpec{
+			Selector:          selector,
+			ChannelSecretName: "line-secret",
+			Expose:            v1alpha1.BotExpose{Type: v1alpha1.IngressExpose, DomainName: "bot.example.com"},
+			Version:           "v0.2.0",
+			Resources: v1alpha1.BotResources{
+				Bot: v1.ResourceRequirements{Limits: v1.ResourceList{v1.ResourceCPU: resource.MustParse("100m")}},
+			},
+			Replicas:    &replicas,
+			Autoscaling: &v1alpha1.BotAutoscaling{MinReplicas: &minReplicas, MaxReplicas: 4},
+			TokenSource: &v1alpha1.BotTokenSource{
+				Type:                 v1alpha1.AssertionTokenSource,
+				ChannelID:            "1234",
+				PrivateKeySecretName: "line-key",
+				TokenLifetime:        &metav1.Duration{Duration: 24 * time.Hour},
+			},
+			RateLimit: &v1alpha1.BotRateLimit{
+				PerSource:    &v1alpha1.TokenBucket{Capacity: 5, RefillInterval: metav1.Duration{Duration: time.Minute}},
+				RuleCooldown: &metav1.Duration{Duration: time.Second},
+				Action:       v1alpha1.RateLimitNotice,
+				Notice:       "Slow down",
+			},
+		},
+		Status: v1alpha1.BotStatus{
+			Phase: v1alpha1.BotActive,
+			Quota: &v1alpha1.BotQuota{Type: "limited", Limit: 1000, TotalUsage: 950},
+			Conditions: []v1alpha1.BotCondition{{
+				Type:   v1alpha1.BotQuotaNearlyExhausted,
+				Status: v1.ConditionTrue,
+				Reason: "QuotaNearlyExhausted",
+			}},
+		},
+	}
+
+	beta := &Bot{}
+	if err := Convert_v1alpha1_Bot_To_v1beta1_Bot(in, beta); err != nil {
+		t.Fatal(err)
+	}
+	out := &v1alpha1.Bot{}
+	if err := Convert_v1beta1_Bot_To_v1alpha1_Bot(beta, out); err != nil {
+		t.Fatal(err)
+	}
+	checkEqual(t, "v1alpha1", out, in)
+
+	back := &Bot{}
+	if err := Convert_v1alpha1_Bot_To_v1beta1_Bot(out, back); err != nil {
+		t.Fatal(err)
+	}
+	checkEqual(t, "v1beta1", back, beta)
+}
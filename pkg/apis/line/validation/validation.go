@@ -0,0 +1,355 @@
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"time"
+
+	linev1alpha1 "github.com/kairen/line-bot-operator/pkg/apis/line/v1alpha1"
+	linev1beta1 "github.com/kairen/line-bot-operator/pkg/apis/line/v1beta1"
+	"github.com/kairen/line-bot-operator/pkg/schedule"
+	"github.com/line/line-bot-sdk-go/linebot"
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// SupportedEventTypes are the LINE webhook event types an Event can handle.
+var SupportedEventTypes = []string{
+	string(linebot.EventTypeMessage),
+	string(linebot.EventTypeFollow),
+	string(linebot.EventTypeUnfollow),
+	string(linebot.EventTypeJoin),
+	string(linebot.EventTypeLeave),
+	string(linebot.EventTypeMemberJoined),
+	string(linebot.EventTypeMemberLeft),
+	string(linebot.EventTypePostback),
+	string(linebot.EventTypeBeacon),
+	string(linebot.EventTypeAccountLink),
+	string(linebot.EventTypeThings),
+}
+
+// ValidateEventSpec checks the fields of an Event that don't depend on other
+// objects, it is shared by the admission webhook and the Event status.
+func ValidateEventSpec(spec *linev1alpha1.EventSpec, path *field.Path) field.ErrorList {
+	errs := ValidateEventType(spec.Type, path.Child("type"))
+	errs = append(errs, ValidateMessages(spec.Type, spec.Messages, path.Child("messages"))...)
+	errs = append(errs, ValidateEventSource(spec.Source, path.Child("source"))...)
+	return append(errs, ValidateEventSchedule(spec.Schedule, path.Child("schedule"))...)
+}
+
+func ValidateEventSchedule(s *linev1alpha1.EventSchedule, path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	if s == nil {
+		return errs
+	}
+
+	if _, err := time.LoadLocation(s.TimeZone); err != nil {
+		errs = append(errs, field.Invalid(path.Child("timeZone"), s.TimeZone, err.Error()))
+	}
+
+	for i, window := range s.Windows {
+		if err := schedule.ValidateWindow(window); err != nil {
+			errs = append(errs, field.Invalid(path.Child("windows").Index(i), window, err.Error()))
+		}
+	}
+
+	errs = append(errs, validateDate(s.StartDate, path.Child("startDate"))...)
+	errs = append(errs, validateDate(s.EndDate, path.Child("endDate"))...)
+	if len(errs) == 0 && s.StartDate != "" && s.EndDate != "" && s.StartDate > s.EndDate {
+		errs = append(errs, field.Invalid(path.Child("endDate"), s.EndDate, "must not be before the start date"))
+	}
+
+	if s.Holidays == nil {
+		return errs
+	}
+	for i, date := range s.Holidays.Dates {
+		errs = append(errs, validateDate(date, path.Child("holidays", "dates").Index(i))...)
+	}
+	if s.Holidays.ConfigMapName != "" {
+		for _, msg := range apivalidation.NameIsDNSSubdomain(s.Holidays.ConfigMapName, false) {
+			errs = append(errs, field.Invalid(path.Child("holidays", "configMapName"), s.Holidays.ConfigMapName, msg))
+		}
+	}
+	return errs
+}
+
+func validateDate(date string, path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	if _, err := time.Parse(schedule.DateLayout, date); date != "" && err != nil {
+		errs = append(errs, field.Invalid(path, date, "must be a date as YYYY-MM-DD"))
+	}
+	return errs
+}
+
+var supportedSourceTypes = []string{
+	string(linebot.EventSourceTypeUser),
+	string(linebot.EventSourceTypeGroup),
+	string(linebot.EventSourceTypeRoom),
+}
+
+func ValidateEventSource(source *linev1alpha1.EventSource, path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	if source == nil {
+		return errs
+	}
+
+	for i, t := range source.Types {
+		switch t {
+		case linebot.EventSourceTypeUser, linebot.EventSourceTypeGroup, linebot.EventSourceTypeRoom:
+		default:
+			errs = append(errs, field.NotSupported(path.Child("types").Index(i), t, supportedSourceTypes))
+		}
+	}
+
+	for i, id := range source.Allow {
+		if id == "" {
+			errs = append(errs, field.Required(path.Child("allow").Index(i), "must be a user, group or room ID"))
+		}
+	}
+	for i, id := range source.Deny {
+		if id == "" {
+			errs = append(errs, field.Required(path.Child("deny").Index(i), "must be a user, group or room ID"))
+		}
+	}
+
+	if source.ConfigMapName != "" {
+		for _, msg := range apivalidation.NameIsDNSSubdomain(source.ConfigMapName, false) {
+			errs = append(errs, field.Invalid(path.Child("configMapName"), source.ConfigMapName, msg))
+		}
+	}
+	return errs
+}
+
+func ValidateEventType(eventType linebot.EventType, path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	if eventType == "" {
+		return append(errs, field.Required(path, ""))
+	}
+
+	for _, t := range SupportedEventTypes {
+		if string(eventType) == t {
+			return errs
+		}
+	}
+	return append(errs, field.NotSupported(path, eventType, SupportedEventTypes))
+}
+
+func ValidateMessages(eventType linebot.EventType, messages []linev1alpha1.Message, path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	keywords := map[string]bool{}
+	for i, msg := range messages {
+		errs = append(errs, ValidateReplies(&msg, path.Index(i))...)
+		errs = append(errs, ValidateMessageI18n(&msg, path.Index(i).Child("i18n"))...)
+
+		keywordsPath := path.Index(i).Child("keywords")
+		if eventType != linebot.EventTypeMessage && len(msg.Keywords) > 0 {
+			errs = append(errs, field.Forbidden(keywordsPath,
+				fmt.Sprintf("keywords are only supported on %q events, not %q", linebot.EventTypeMessage, eventType)))
+			continue
+		}
+
+		for j, keyword := range msg.Keywords {
+			if keywords[keyword] {
+				errs = append(errs, field.Duplicate(keywordsPath.Index(j), keyword))
+			}
+			keywords[keyword] = true
+		}
+
+		patternsPath := path.Index(i).Child("patterns")
+		if eventType != linebot.EventTypeMessage && len(msg.Patterns) > 0 {
+			errs = append(errs, field.Forbidden(patternsPath,
+				fmt.Sprintf("patterns are only supported on %q events, not %q", linebot.EventTypeMessage, eventType)))
+			continue
+		}
+
+		for j, pattern := range msg.Patterns {
+			if _, err := regexp.Compile(pattern); err != nil {
+				errs = append(errs, field.Invalid(patternsPath.Index(j), pattern, err.Error()))
+			}
+		}
+	}
+	return errs
+}
+
+var supportedStrategies = []string{
+	string(linev1alpha1.SelectionRandom),
+	string(linev1alpha1.SelectionRoundRobin),
+	string(linev1alpha1.SelectionNoRepeat),
+}
+
+// ValidateReplies checks the reply variants of a message and their selection.
+func ValidateReplies(msg *linev1alpha1.Message, path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	repliesPath := path.Child("replies")
+	if len(msg.Replies) > 0 && msg.Reply != "" {
+		errs = append(errs, field.Forbidden(repliesPath, "may not be set with reply"))
+	}
+
+	for i, variant := range msg.Replies {
+		if variant.Weight < 0 {
+			errs = append(errs, field.Invalid(repliesPath.Index(i).Child("weight"), variant.Weight, "must be greater than or equal to 0"))
+		}
+		if variant.Reply == "" {
+			errs = append(errs, field.Required(repliesPath.Index(i).Child("reply"), ""))
+		}
+	}
+
+	selection := msg.Selection
+	if selection == nil {
+		return errs
+	}
+
+	selectionPath := path.Child("selection")
+	switch selection.Strategy {
+	case "", linev1alpha1.SelectionRandom, linev1alpha1.SelectionRoundRobin:
+	case linev1alpha1.SelectionNoRepeat:
+		if window := selection.Window; int(window) >= len(msg.Replies) || (window == 0 && len(msg.Replies) < 2) {
+			errs = append(errs, field.Invalid(selectionPath.Child("window"), window, "must be less than the number of replies"))
+		}
+	default:
+		errs = append(errs, field.NotSupported(selectionPath.Child("strategy"), selection.Strategy, supportedStrategies))
+	}
+	if selection.Window < 0 {
+		errs = append(errs, field.Invalid(selectionPath.Child("window"), selection.Window, "must be greater than or equal to 0"))
+	}
+	return errs
+}
+
+var (
+	localePattern      = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+	placeholderPattern = regexp.MustCompile(`\$\{([A-Za-z0-9_]+)\}`)
+)
+
+// ValidateMessageI18n checks the locales of a message. The replies of the bundles
+// are checked by the operator when it resolves them.
+func ValidateMessageI18n(msg *linev1alpha1.Message, path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	i18n := msg.I18n
+	if i18n == nil {
+		return errs
+	}
+
+	if len(msg.Replies) > 0 {
+		errs = append(errs, field.Forbidden(path, "may not be set with replies"))
+	}
+
+	for locale := range i18n.Replies {
+		if !localePattern.MatchString(locale) {
+			errs = append(errs, field.Invalid(path.Child("replies").Key(locale), locale, "must be a locale, e.g. zh-TW or ja"))
+		}
+	}
+	errs = append(errs, ValidatePlaceholders(msg.Reply, i18n.Replies, path.Child("replies"))...)
+
+	if i18n.Fallback != "" && i18n.ConfigMapName == "" {
+		if _, ok := i18n.Replies[i18n.Fallback]; !ok {
+			errs = append(errs, field.NotFound(path.Child("fallback"), i18n.Fallback))
+		}
+	}
+
+	if i18n.ConfigMapName != "" {
+		for _, msg := range apivalidation.NameIsDNSSubdomain(i18n.ConfigMapName, false) {
+			errs = append(errs, field.Invalid(path.Child("configMapName"), i18n.ConfigMapName, msg))
+		}
+		if i18n.Key == "" {
+			errs = append(errs, field.Required(path.Child("key"), "the bundle key is required with configMapName"))
+		}
+	}
+	return errs
+}
+
+// ValidatePlaceholders checks that the replies of all the locales have the same
+// placeholders as the reply, or as each other when the reply is empty.
+func ValidatePlaceholders(reply string, replies map[string]string, path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	locales := make([]string, 0, len(replies))
+	for locale := range replies {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+
+	if reply == "" && len(locales) > 0 {
+		reply = replies[locales[0]]
+	}
+	expected := Placeholders(reply)
+	for _, locale := range locales {
+		if found := Placeholders(replies[locale]); !reflect.DeepEqual(found, expected) {
+			errs = append(errs, field.Invalid(path.Key(locale), replies[locale],
+				fmt.Sprintf("has the placeholders %v instead of %v", found, expected)))
+		}
+	}
+	return errs
+}
+
+// Placeholders returns the sorted names of the ${name} placeholders of a text.
+func Placeholders(text string) []string {
+	names := []string{}
+	seen := map[string]bool{}
+	for _, match := range placeholderPattern.FindAllStringSubmatch(text, -1) {
+		if !seen[match[1]] {
+			seen[match[1]] = true
+			names = append(names, match[1])
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ValidateDialogSpec checks that the states of a Dialog are consistent, e.g. that
+// the transitions and slots lead to existing states.
+func ValidateDialogSpec(spec *linev1beta1.DialogSpec, path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	statesPath := path.Child("states")
+	if len(spec.States) == 0 {
+		return append(errs, field.Required(statesPath, "a dialog needs at least one state"))
+	}
+
+	states := map[string]bool{}
+	for i, state := range spec.States {
+		if state.Name == "" {
+			errs = append(errs, field.Required(statesPath.Index(i).Child("name"), ""))
+		} else if states[state.Name] {
+			errs = append(errs, field.Duplicate(statesPath.Index(i).Child("name"), state.Name))
+		}
+		states[state.Name] = true
+	}
+
+	if spec.InitialState != "" && !states[spec.InitialState] {
+		errs = append(errs, field.NotFound(path.Child("initialState"), spec.InitialState))
+	}
+
+	if spec.Timeout != nil && spec.Timeout.Duration <= 0 {
+		errs = append(errs, field.Invalid(path.Child("timeout"), spec.Timeout.Duration.String(), "must be greater than 0"))
+	}
+
+	for i, state := range spec.States {
+		statePath := statesPath.Index(i)
+		if state.Slot != nil {
+			slotPath := statePath.Child("slot")
+			if state.Slot.Name == "" {
+				errs = append(errs, field.Required(slotPath.Child("name"), ""))
+			}
+			if !states[state.Slot.Next] {
+				errs = append(errs, field.NotFound(slotPath.Child("next"), state.Slot.Next))
+			}
+			if _, err := regexp.Compile(state.Slot.Pattern); err != nil {
+				errs = append(errs, field.Invalid(slotPath.Child("pattern"), state.Slot.Pattern, err.Error()))
+			}
+		}
+
+		for j, transition := range state.Transitions {
+			transitionPath := statePath.Child("transitions").Index(j)
+			if !states[transition.To] {
+				errs = append(errs, field.NotFound(transitionPath.Child("to"), transition.To))
+			}
+			if len(transition.Keywords) == 0 && transition.Postback == "" && transition.Pattern == "" {
+				errs = append(errs, field.Required(transitionPath, "needs keywords, a postback or a pattern"))
+			}
+			if _, err := regexp.Compile(transition.Pattern); err != nil {
+				errs = append(errs, field.Invalid(transitionPath.Child("pattern"), transition.Pattern, err.Error()))
+			}
+		}
+	}
+	return errs
+}
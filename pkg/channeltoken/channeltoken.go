@@ -0,0 +1,189 @@
+package channeltoken
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultAPIBaseURL is the base URL of the LINE Messaging API.
+const DefaultAPIBaseURL = "https://api.line.me"
+
+// DefaultTimeout bounds the requests to the Messaging API, which are sent by the
+// single worker syncing the bots.
+const DefaultTimeout = 10 * time.Second
+
+const (
+	shortLivedTokenPath  = "/v2/oauth/accessToken"
+	shortLivedRevokePath = "/v2/oauth/revoke"
+	tokenPath            = "/oauth2/v2.1/token"
+	revokePath           = "/oauth2/v2.1/revoke"
+
+	assertionType     = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+	assertionAudience = "https://api.line.me/"
+	assertionLifetime = 30 * time.Minute
+)
+
+// Token is a channel access token issued by the Messaging API.
+type Token struct {
+	AccessToken    string
+	KeyID          string
+	ExpirationTime time.Time
+}
+
+// Client issues and revokes channel access tokens.
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+func NewClient() *Client {
+	return &Client{BaseURL: DefaultAPIBaseURL, HTTPClient: &http.Client{Timeout: DefaultTimeout}}
+}
+
+// IssueShortLived issues a short-lived channel access token, which is valid for
+// 30 days, with the channel ID and secret.
+func (c *Client) IssueShortLived(channelID, channelSecret string) (*Token, error) {
+	return c.issue(shortLivedTokenPath, url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {channelID},
+		"client_secret": {channelSecret},
+	})
+}
+
+// IssueWithAssertion issues a channel access token v2.1 with a signed channel
+// assertion, see NewAssertion.
+func (c *Client) IssueWithAssertion(assertion string) (*Token, error) {
+	return c.issue(tokenPath, url.Values{
+		"grant_type":            {"client_credentials"},
+		"client_assertion_type": {assertionType},
+		"client_assertion":      {assertion},
+	})
+}
+
+// RevokeShortLived revokes a token issued by IssueShortLived.
+func (c *Client) RevokeShortLived(accessToken string) error {
+	return c.post(shortLivedRevokePath, url.Values{"access_token": {accessToken}}, nil)
+}
+
+// Revoke revokes a token issued by IssueWithAssertion.
+func (c *Client) Revoke(channelID, channelSecret, accessToken string) error {
+	return c.post(revokePath, url.Values{
+		"client_id":     {channelID},
+		"client_secret": {channelSecret},
+		"access_token":  {accessToken},
+	}, nil)
+}
+
+func (c *Client) issue(path string, form url.Values) (*Token, error) {
+	resp := struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+		KeyID       string `json:"key_id"`
+	}{}
+
+	now := time.Now()
+	if err := c.post(path, form, &resp); err != nil {
+		return nil, err
+	}
+
+	if resp.AccessToken == "" {
+		return nil, fmt.Errorf("no access token in the response of %s", path)
+	}
+
+	return &Token{
+		AccessToken:    resp.AccessToken,
+		KeyID:          resp.KeyID,
+		ExpirationTime: now.Add(time.Duration(resp.ExpiresIn) * time.Second),
+	}, nil
+}
+
+func (c *Client) post(path string, form url.Values, out interface{}) error {
+	resp, err := c.HTTPClient.PostForm(strings.TrimSuffix(c.BaseURL, "/")+path, form)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s: %s", path, resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(body, out)
+}
+
+// NewAssertion signs a channel assertion JWT with the private key registered for
+// the channel. The issued token expires after tokenLifetime, 30 days at most.
+func NewAssertion(channelID, keyID string, key *rsa.PrivateKey, tokenLifetime time.Duration) (string, error) {
+	header, err := json.Marshal(map[string]string{
+		"alg": "RS256",
+		"typ": "JWT",
+		"kid": keyID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	payload, err := json.Marshal(map[string]interface{}{
+		"iss":       channelID,
+		"sub":       channelID,
+		"aud":       assertionAudience,
+		"exp":       now.Add(assertionLifetime).Unix(),
+		"token_exp": int64(tokenLifetime / time.Second),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	encoding := base64.RawURLEncoding
+	unsigned := encoding.EncodeToString(header) + "." + encoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return unsigned + "." + encoding.EncodeToString(signature), nil
+}
+
+// ParsePrivateKey parses a PEM encoded RSA private key, in PKCS #1 or PKCS #8 form.
+func ParsePrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key is not an RSA key")
+	}
+	return rsaKey, nil
+}
@@ -0,0 +1,293 @@
+package channeltoken
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newKey(t *testing.T) *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestParsePrivateKey(t *testing.T) {
+	key := newKey(t)
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecPKCS8, err := x509.MarshalPKCS8PrivateKey(ecKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encode := func(blockType string, der []byte) []byte {
+		return pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	}
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr string
+	}{
+		{"PKCS #1", encode("RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(key)), ""},
+		{"PKCS #8", encode("PRIVATE KEY", pkcs8), ""},
+		{"not RSA", encode("PRIVATE KEY", ecPKCS8), "not an RSA key"},
+		{"not a key", encode("PRIVATE KEY", []byte("garbage")), "asn1"},
+		{"not PEM", []byte("-----BEGIN nothing"), "no PEM data"},
+		{"empty", nil, "no PEM data"},
+	}
+
+	for _, test := range tests {
+		got, err := ParsePrivateKey(test.data)
+		if test.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("%s: got error %v, want %q", test.name, err, test.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+		} else if got.N.Cmp(key.N) != 0 {
+			t.Errorf("%s: got another key", test.name)
+		}
+	}
+}
+
+func TestNewAssertion(t *testing.T) {
+	key := newKey(t)
+	tests := []struct {
+		lifetime      time.Duration
+		wantTokenExp  int64
+		wantKeyHeader string
+	}{
+		{24 * time.Hour, 86400, "key-1"},
+		{30 * 24 * time.Hour, 2592000, "key-2"},
+		{90 * time.Minute, 5400, ""},
+	}
+
+	for _, test := range tests {
+		before := time.Now()
+		assertion, err := NewAssertion("1234", test.wantKeyHeader, key, test.lifetime)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		parts := strings.Split(assertion, ".")
+		if len(parts) != 3 {
+			t.Fatalf("got %d parts in %q, want 3", len(parts), assertion)
+		}
+		encoding := base64.RawURLEncoding
+		signature, err := encoding.DecodeString(parts[2])
+		if err != nil {
+			t.Fatal(err)
+		}
+		digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature); err != nil {
+			t.Errorf("lifetime %v: got an invalid signature: %v", test.lifetime, err)
+		}
+
+		header, payload := map[string]string{}, map[string]interface{}{}
+		for i, out := range []interface{}{&header, &payload} {
+			data, err := encoding.DecodeString(parts[i])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal(data, out); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if header["alg"] != "RS256" || header["kid"] != test.wantKeyHeader {
+			t.Errorf("lifetime %v: got header %v", test.lifetime, header)
+		}
+		if payload["iss"] != "1234" || payload["sub"] != "1234" || payload["aud"] != assertionAudience {
+			t.Errorf("lifetime %v: got payload %v", test.lifetime, payload)
+		}
+		if got := int64(payload["token_exp"].(float64)); got != test.wantTokenExp {
+			t.Errorf("lifetime %v: got token_exp %d, want %d", test.lifetime, got, test.wantTokenExp)
+		}
+		exp := time.Unix(int64(payload["exp"].(float64)), 0)
+		if exp.Before(before.Add(assertionLifetime).Truncate(time.Second)) || exp.After(time.Now().Add(assertionLifetime)) {
+			t.Errorf("lifetime %v: got exp %v, want in %v", test.lifetime, exp, assertionLifetime)
+		}
+	}
+}
+
+// newTestServer answers a path with a status and body, and records the forms it
+// receives.
+func newTestServer(t *testing.T, path string, status int, body string, forms *[]map[string]string) (*Client, *httptest.Server) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != path {
+			http.NotFound(w, r)
+			return
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Error(err)
+		}
+		form := map[string]string{}
+		for key := range r.PostForm {
+			form[key] = r.PostForm.Get(key)
+		}
+		*forms = append(*forms, form)
+
+		w.WriteHeader(status)
+		fmt.Fprint(w, body)
+	}))
+
+	client := NewClient()
+	client.BaseURL = server.URL + "/"
+	client.HTTPClient = server.Client()
+	return client, server
+}
+
+func TestIssue(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		issue   func(*Client) (*Token, error)
+		status  int
+		body    string
+		form    map[string]string
+		wantErr string
+	}{
+		{
+			name:   "short-lived",
+			path:   shortLivedTokenPath,
+			issue:  func(c *Client) (*Token, error) { return c.IssueShortLived("1234", "secret") },
+			status: http.StatusOK,
+			body:   `{"access_token":"token","expires_in":2592000,"token_type":"Bearer"}`,
+			form:   map[string]string{"grant_type": "client_credentials", "client_id": "1234", "client_secret": "secret"},
+		},
+		{
+			name:   "assertion",
+			path:   tokenPath,
+			issue:  func(c *Client) (*Token, error) { return c.IssueWithAssertion("jwt") },
+			status: http.StatusOK,
+			body:   `{"access_token":"token","expires_in":2592000,"key_id":"kid"}`,
+			form: map[string]string{
+				"grant_type":            "client_credentials",
+				"client_assertion_type": assertionType,
+				"client_assertion":      "jwt",
+			},
+		},
+		{
+			name:    "bad request",
+			path:    tokenPath,
+			issue:   func(c *Client) (*Token, error) { return c.IssueWithAssertion("jwt") },
+			status:  http.StatusBadRequest,
+			body:    `{"error":"invalid_client"}`,
+			wantErr: "400 Bad Request: {\"error\":\"invalid_client\"}",
+		},
+		{
+			name:    "no token",
+			path:    shortLivedTokenPath,
+			issue:   func(c *Client) (*Token, error) { return c.IssueShortLived("1234", "secret") },
+			status:  http.StatusOK,
+			body:    `{}`,
+			wantErr: "no access token",
+		},
+		{
+			name:    "invalid body",
+			path:    shortLivedTokenPath,
+			issue:   func(c *Client) (*Token, error) { return c.IssueShortLived("1234", "secret") },
+			status:  http.StatusOK,
+			body:    `<html>`,
+			wantErr: "invalid character",
+		},
+	}
+
+	for _, test := range tests {
+		forms := []map[string]string{}
+		client, server := newTestServer(t, test.path, test.status, test.body, &forms)
+		defer server.Close()
+
+		before := time.Now()
+		token, err := test.issue(client)
+		if test.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("%s: got error %v, want %q", test.name, err, test.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+
+		if token.AccessToken != "token" || token.ExpirationTime.Before(before.Add(30*24*time.Hour)) {
+			t.Errorf("%s: got token %+v", test.name, token)
+		}
+		if len(forms) != 1 || fmt.Sprint(forms[0]) != fmt.Sprint(test.form) {
+			t.Errorf("%s: got forms %v, want %v", test.name, forms, test.form)
+		}
+	}
+}
+
+func TestRevoke(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		revoke  func(*Client) error
+		status  int
+		form    map[string]string
+		wantErr bool
+	}{
+		{
+			name:   "short-lived",
+			path:   shortLivedRevokePath,
+			revoke: func(c *Client) error { return c.RevokeShortLived("token") },
+			status: http.StatusOK,
+			form:   map[string]string{"access_token": "token"},
+		},
+		{
+			name:   "v2.1",
+			path:   revokePath,
+			revoke: func(c *Client) error { return c.Revoke("1234", "secret", "token") },
+			status: http.StatusOK,
+			form:   map[string]string{"client_id": "1234", "client_secret": "secret", "access_token": "token"},
+		},
+		{
+			name:    "unauthorized",
+			path:    revokePath,
+			revoke:  func(c *Client) error { return c.Revoke("1234", "wrong", "token") },
+			status:  http.StatusUnauthorized,
+			form:    map[string]string{"client_id": "1234", "client_secret": "wrong", "access_token": "token"},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		forms := []map[string]string{}
+		client, server := newTestServer(t, test.path, test.status, "", &forms)
+		defer server.Close()
+
+		err := test.revoke(client)
+		if (err != nil) != test.wantErr {
+			t.Errorf("%s: got error %v, want error %v", test.name, err, test.wantErr)
+		}
+		if len(forms) != 1 || fmt.Sprint(forms[0]) != fmt.Sprint(test.form) {
+			t.Errorf("%s: got forms %v, want %v", test.name, forms, test.form)
+		}
+	}
+}
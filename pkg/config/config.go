@@ -0,0 +1,183 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	linev1alpha1 "github.com/kairen/line-bot-operator/pkg/apis/line/v1alpha1"
+	"github.com/kairen/line-bot-operator/pkg/channeltoken"
+	"github.com/kairen/line-bot-operator/pkg/constants"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	defaultResyncPeriod = 5 * time.Minute
+	reloadInterval      = 10 * time.Second
+
+	defaultQuotaPollInterval = 15 * time.Minute
+	defaultQuotaThreshold    = 90
+)
+
+// Config is the operator configuration, it is read from the --config file.
+type Config struct {
+	BotImage           string                     `json:"botImage"`
+	NgrokImage         string                     `json:"ngrokImage"`
+	ImagePullSecrets   []v1.LocalObjectReference  `json:"imagePullSecrets,omitempty"`
+	ServiceAccountName string                     `json:"serviceAccountName"`
+	Resources          Resources                  `json:"resources,omitempty"`
+	ExposeType         linev1alpha1.BotExposeType `json:"exposeType"`
+	ResyncPeriod       metav1.Duration            `json:"resyncPeriod"`
+	// APIBaseURL is the base URL of the Messaging API, e.g. of a local stub.
+	APIBaseURL string      `json:"apiBaseURL"`
+	Quota      QuotaConfig `json:"quota,omitempty"`
+}
+
+// QuotaConfig is the polling of the message quota of the active bots.
+type QuotaConfig struct {
+	// PollInterval is the time between two polls of a bot, 0 disables polling.
+	PollInterval metav1.Duration `json:"pollInterval"`
+	// Threshold is the percentage of the quota whose usage sets the
+	// QuotaNearlyExhausted condition.
+	Threshold int32 `json:"threshold"`
+	// Insights also polls the follower and delivery insights.
+	Insights bool `json:"insights,omitempty"`
+}
+
+// Resources are the default resources of the bot containers.
+type Resources struct {
+	Bot   v1.ResourceRequirements `json:"bot,omitempty"`
+	Ngrok v1.ResourceRequirements `json:"ngrok,omitempty"`
+}
+
+func NewDefaultConfig() *Config {
+	return &Config{
+		BotImage:           constants.BotImageName,
+		NgrokImage:         constants.NgrokImageName,
+		ServiceAccountName: constants.ServiceAccountName,
+		ExposeType:         linev1alpha1.NgrokExpose,
+		ResyncPeriod:       metav1.Duration{Duration: defaultResyncPeriod},
+		APIBaseURL:         channeltoken.DefaultAPIBaseURL,
+		Quota: QuotaConfig{
+			PollInterval: metav1.Duration{Duration: defaultQuotaPollInterval},
+			Threshold:    defaultQuotaThreshold,
+		},
+	}
+}
+
+// Parse reads a YAML configuration, the unset fields keep their defaults.
+func Parse(data []byte) (*Config, error) {
+	config := NewDefaultConfig()
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, err
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// Validate checks the values the defaults can't stand in for.
+func (c *Config) Validate() error {
+	if c.ResyncPeriod.Duration <= 0 {
+		return fmt.Errorf("resyncPeriod must be positive, got %s", c.ResyncPeriod.Duration)
+	}
+	switch c.ExposeType {
+	case linev1alpha1.NgrokExpose, linev1alpha1.IngressExpose, linev1alpha1.LoadBalancerExpose:
+	default:
+		return fmt.Errorf("exposeType must be one of %s, %s or %s, got %q",
+			linev1alpha1.NgrokExpose, linev1alpha1.IngressExpose, linev1alpha1.LoadBalancerExpose, c.ExposeType)
+	}
+	if c.Quota.Threshold < 1 || c.Quota.Threshold > 100 {
+		return fmt.Errorf("quota.threshold must be between 1 and 100, got %d", c.Quota.Threshold)
+	}
+	return nil
+}
+
+// Store holds the current configuration and reloads it when the file changes.
+type Store struct {
+	path   string
+	data   []byte
+	mu     sync.RWMutex
+	config *Config
+}
+
+func NewStore(path string) (*Store, error) {
+	s := &Store{path: path, config: NewDefaultConfig()}
+	if path == "" {
+		return s, nil
+	}
+
+	if _, err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Get returns the current configuration, the callers must not modify it.
+func (s *Store) Get() *Config {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.config
+}
+
+// Run polls the configuration file until stopCh is closed. Mounted ConfigMaps are
+// updated in place, so the operator does not need to be restarted.
+func (s *Store) Run(stopCh chan struct{}) {
+	if s.path == "" {
+		return
+	}
+
+	go func() {
+		for {
+			select {
+			case <-stopCh:
+				return
+			case <-time.After(reloadInterval):
+				changed, err := s.load()
+				if err != nil {
+					klog.Errorf("Failed to reload config from %s, keeping the previous one: %+v.", s.path, err)
+					continue
+				}
+				if changed {
+					klog.Infof("Success to reload config from %s.", s.path)
+				}
+			}
+		}
+	}()
+}
+
+func (s *Store) load() (bool, error) {
+	data, err := ioutil.ReadFile(s.path)
+	if err != nil {
+		return false, err
+	}
+
+	if s.data != nil && bytes.Equal(s.data, data) {
+		return false, nil
+	}
+
+	config, err := Parse(data)
+	if err != nil {
+		return false, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data = data
+	s.config = config
+	return true, nil
+}
+
+// SetBotDefaults defaults a Bot spec, using the configured expose type.
+func (c *Config) SetBotDefaults(bot *linev1alpha1.Bot) {
+	if bot.Spec.Expose.Type == "" {
+		bot.Spec.Expose.Type = c.ExposeType
+	}
+	linev1alpha1.SetBotDefaults(bot)
+}
@@ -5,3 +5,62 @@ const (
 	NgrokImageName     = "kairen/ngrok"
 	ServiceAccountName = "bot-admin"
 )
+
+// Keys of the channel secret referenced by spec.channelSecretName.
+const (
+	ChannelSecretKey = "channelSecret"
+	ChannelTokenKey  = "channelToken"
+	NgrokTokenKey    = "ngrokToken"
+)
+
+// Keys of the private key secret referenced by spec.tokenSource.privateKeySecretName.
+const (
+	PrivateKeyKey = "privateKey"
+	KeyIDKey      = "keyID"
+)
+
+// Keys of the ConfigMap referenced by the source of an Event, one ID per line.
+const (
+	SourceAllowKey = "allow"
+	SourceDenyKey  = "deny"
+)
+
+// HolidaysKey is the key of the ConfigMap referenced by the holidays of an Event
+// schedule, one YYYY-MM-DD date per line.
+const HolidaysKey = "holidays"
+
+// Annotations managed by the operator.
+const (
+	TokenIssuedAnnotation     = "line.you/token-issued"
+	TokenExpirationAnnotation = "line.you/token-expiration"
+	RestartedAtAnnotation     = "line.you/restartedAt"
+	SecretHashAnnotation      = "line.you/secret-hash"
+	RulesVersionAnnotation    = "line.you/rules-version"
+	RulesGenerationAnnotation = "line.you/rules-generation"
+)
+
+// PausedAnnotation suspends the reconciliation of a Bot when set to "true", e.g.
+// while debugging it.
+const PausedAnnotation = "line.you/paused"
+
+// Reasons of the Kubernetes events recorded by the controllers.
+const (
+	ReasonCreated        = "Created"
+	ReasonFailedCreate   = "FailedCreate"
+	ReasonSecretNotFound = "SecretNotFound"
+	ReasonBindingUpdated = "BindingUpdated"
+	ReasonFailedBinding  = "FailedBinding"
+	ReasonNoBotSelected  = "NoBotSelected"
+	ReasonMigrated       = "Migrated"
+	ReasonTokenIssued    = "TokenIssued"
+	ReasonFailedIssue    = "FailedIssueToken"
+	ReasonTokenRevoked   = "TokenRevoked"
+	ReasonSecretChanged  = "SecretChanged"
+	ReasonUpdated        = "Updated"
+	ReasonDeleted        = "Deleted"
+	ReasonRulesCompiled  = "RulesCompiled"
+	ReasonFailedCompile  = "FailedCompileRules"
+
+	ReasonQuotaNearlyExhausted = "QuotaNearlyExhausted"
+	ReasonFailedPollQuota      = "FailedPollQuota"
+)
@@ -0,0 +1,212 @@
+package conversation
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"time"
+
+	linev1beta1 "github.com/kairen/line-bot-operator/pkg/apis/line/v1beta1"
+	"github.com/kairen/line-bot-operator/pkg/apis/line/validation"
+	"github.com/line/line-bot-sdk-go/linebot"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// Conversation is the state of a dialog for a user, group or room.
+type Conversation struct {
+	Dialog    string            `json:"dialog"`
+	State     string            `json:"state"`
+	Slots     map[string]string `json:"slots,omitempty"`
+	UpdatedAt time.Time         `json:"updatedAt"`
+}
+
+// Input is what the user sent, the text of a message or the data of a postback.
+type Input struct {
+	Text     string
+	Postback string
+}
+
+// Result of a step, Conversation is nil once the dialog is done.
+type Result struct {
+	Conversation *Conversation
+	Reply        []linev1beta1.ReplyMessage
+	// Handled is false when the dialog didn't expect the input, which is then
+	// left to the rules.
+	Handled bool
+}
+
+// Machine runs the states of a dialog, it is read-only and safe for concurrent use.
+type Machine struct {
+	name    string
+	spec    linev1beta1.DialogSpec
+	initial string
+	states  map[string]*state
+}
+
+type state struct {
+	*linev1beta1.DialogState
+	slotPattern *regexp.Regexp
+	patterns    []*regexp.Regexp
+}
+
+// NewMachine checks and compiles the spec of a dialog.
+func NewMachine(name string, spec *linev1beta1.DialogSpec) (*Machine, error) {
+	if errs := validation.ValidateDialogSpec(spec, field.NewPath("spec")); len(errs) > 0 {
+		return nil, fmt.Errorf("invalid Dialog %s: %v", name, errs.ToAggregate())
+	}
+
+	m := &Machine{
+		name:    name,
+		spec:    *spec.DeepCopy(),
+		initial: spec.InitialState,
+		states:  map[string]*state{},
+	}
+	if m.initial == "" {
+		m.initial = spec.States[0].Name
+	}
+
+	for i := range m.spec.States {
+		s := &state{DialogState: &m.spec.States[i]}
+		if s.Slot != nil {
+			s.slotPattern = regexp.MustCompile(s.Slot.Pattern)
+		}
+		for _, transition := range s.Transitions {
+			s.patterns = append(s.patterns, regexp.MustCompile(transition.Pattern))
+		}
+		m.states[s.Name] = s
+	}
+	return m, nil
+}
+
+func (m *Machine) Name() string {
+	return m.name
+}
+
+// Step advances a conversation with the input, a nil or expired conversation
+// starts again from the initial state.
+func (m *Machine) Step(conv *Conversation, in Input, now time.Time) *Result {
+	if conv == nil || conv.Dialog != m.name || m.expired(conv, now) || m.states[conv.State] == nil {
+		conv = &Conversation{Dialog: m.name, State: m.initial}
+	}
+
+	next := &Conversation{Dialog: m.name, State: conv.State, Slots: map[string]string{}, UpdatedAt: now}
+	for key, value := range conv.Slots {
+		next.Slots[key] = value
+	}
+
+	current := m.states[conv.State]
+	if to, ok := current.transition(in, next.Slots); ok {
+		return m.enter(next, to)
+	}
+
+	// A new conversation only starts with a transition of the initial state.
+	if current.Slot == nil || in.Text == "" || conv.UpdatedAt.IsZero() {
+		return &Result{Conversation: conv}
+	}
+
+	if !current.slotPattern.MatchString(in.Text) {
+		return &Result{Conversation: next, Reply: Expand(current.Slot.InvalidReply, next.Slots), Handled: true}
+	}
+
+	value := in.Text
+	if match := current.slotPattern.FindStringSubmatch(in.Text); len(match) > 1 {
+		value = match[1]
+	}
+	next.Slots[current.Slot.Name] = value
+	return m.enter(next, current.Slot.Next)
+}
+
+// transition returns the state of the first transition triggered by the input,
+// the named capture groups of its pattern are stored in the slots.
+func (s *state) transition(in Input, slots map[string]string) (string, bool) {
+	for i, transition := range s.Transitions {
+		if in.Postback != "" && transition.Postback == in.Postback {
+			return transition.To, true
+		}
+		if in.Text == "" {
+			continue
+		}
+
+		for _, keyword := range transition.Keywords {
+			if keyword == in.Text {
+				return transition.To, true
+			}
+		}
+
+		if transition.Pattern == "" {
+			continue
+		}
+		match := s.patterns[i].FindStringSubmatch(in.Text)
+		if match == nil {
+			continue
+		}
+		for j, name := range s.patterns[i].SubexpNames() {
+			if name != "" {
+				slots[name] = match[j]
+			}
+		}
+		return transition.To, true
+	}
+	return "", false
+}
+
+func (m *Machine) enter(conv *Conversation, name string) *Result {
+	conv.State = name
+	to := m.states[name]
+	result := &Result{Conversation: conv, Reply: Expand(to.Reply, conv.Slots), Handled: true}
+	if to.Final {
+		result.Conversation = nil
+	}
+	return result
+}
+
+func (m *Machine) expired(conv *Conversation, now time.Time) bool {
+	return m.spec.Timeout != nil && now.Sub(conv.UpdatedAt) > m.spec.Timeout.Duration
+}
+
+// Expand replaces the ${name} placeholders of the text replies, e.g. the slots of
+// a dialog or the values a bot fills in the localized replies.
+func Expand(replies []linev1beta1.ReplyMessage, values map[string]string) []linev1beta1.ReplyMessage {
+	out := make([]linev1beta1.ReplyMessage, len(replies))
+	for i, reply := range replies {
+		out[i] = reply
+		if reply.Type == linebot.MessageTypeText {
+			out[i].Text = os.Expand(reply.Text, func(key string) string {
+				return values[key]
+			})
+		}
+	}
+	return out
+}
+
+// Converse advances the conversation of a source with the machine of its ongoing
+// dialog, or starts the first dialog whose initial state handles the input. The
+// result is nil when no dialog handles the input.
+func Converse(store Store, machines []*Machine, source string, in Input, now time.Time) (*Result, error) {
+	conv, err := store.Get(source)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, m := range machines {
+		if conv != nil && conv.Dialog == m.name {
+			if result := m.Step(conv, in, now); result.Handled {
+				return result, save(store, source, result)
+			}
+		}
+	}
+
+	for _, m := range machines {
+		if result := m.Step(nil, in, now); result.Handled {
+			return result, save(store, source, result)
+		}
+	}
+	return nil, nil
+}
+
+func save(store Store, source string, result *Result) error {
+	if result.Conversation == nil {
+		return store.Delete(source)
+	}
+	return store.Put(source, result.Conversation)
+}
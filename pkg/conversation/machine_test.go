@@ -0,0 +1,280 @@
+package conversation
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	linev1beta1 "github.com/kairen/line-bot-operator/pkg/apis/line/v1beta1"
+	"github.com/line/line-bot-sdk-go/linebot"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func reply(text string) []linev1beta1.ReplyMessage {
+	return []linev1beta1.ReplyMessage{{Type: linebot.MessageTypeText, Text: text}}
+}
+
+func newPizzaMachine(t *testing.T) *Machine {
+	m, err := NewMachine("pizza", &linev1beta1.DialogSpec{
+		Timeout: &metav1.Duration{Duration: 10 * time.Minute},
+		States: []linev1beta1.DialogState{
+			{
+				Name: "start",
+				Transitions: []linev1beta1.DialogTransition{
+					{To: "size", Keywords: []string{"order", "pizza"}},
+					{To: "menu", Postback: "action=menu"},
+				},
+			},
+			{
+				Name:        "size",
+				Reply:       reply("Which size?"),
+				Slot:        &linev1beta1.DialogSlot{Name: "size", Pattern: "^(small|large)$", InvalidReply: reply("Small or large?"), Next: "address"},
+				Transitions: []linev1beta1.DialogTransition{{To: "cancelled", Keywords: []string{"cancel"}}},
+			},
+			{
+				Name:        "address",
+				Reply:       reply("Deliver a ${size} pizza where?"),
+				Slot:        &linev1beta1.DialogSlot{Name: "address", Next: "done"},
+				Transitions: []linev1beta1.DialogTransition{{To: "done", Pattern: "^same as (?P<address>last time)$"}},
+			},
+			{Name: "done", Reply: reply("A ${size} pizza to ${address}"), Final: true},
+			{Name: "menu", Reply: reply("Menu"), Final: true},
+			{Name: "cancelled", Reply: reply("Cancelled"), Final: true},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func newWeatherMachine(t *testing.T, keyword string) *Machine {
+	m, err := NewMachine("weather", &linev1beta1.DialogSpec{
+		InitialState: "start",
+		States: []linev1beta1.DialogState{
+			{Name: "city", Reply: reply("Which city?"), Slot: &linev1beta1.DialogSlot{Name: "city", Next: "forecast"}},
+			{Name: "start", Transitions: []linev1beta1.DialogTransition{{To: "city", Keywords: []string{keyword}}}},
+			{Name: "forecast", Reply: reply("Sunny in ${city}"), Final: true},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+type turn struct {
+	in      Input
+	at      time.Duration
+	state   string
+	reply   string
+	handled bool
+}
+
+func replyText(replies []linev1beta1.ReplyMessage) string {
+	texts := []string{}
+	for _, reply := range replies {
+		texts = append(texts, reply.Text)
+	}
+	return strings.Join(texts, "\n")
+}
+
+func TestStep(t *testing.T) {
+	tests := []struct {
+		name  string
+		turns []turn
+	}{
+		{
+			name: "slot filling",
+			turns: []turn{
+				{in: Input{Text: "hello"}, state: "start"},
+				{in: Input{Text: "order"}, state: "size", reply: "Which size?", handled: true},
+				{in: Input{Text: "medium"}, state: "size", reply: "Small or large?", handled: true},
+				{in: Input{Text: "large"}, state: "address", reply: "Deliver a large pizza where?", handled: true},
+				{in: Input{Text: "Taipei 101"}, reply: "A large pizza to Taipei 101", handled: true},
+			},
+		},
+		{
+			name: "transition pattern",
+			turns: []turn{
+				{in: Input{Text: "pizza"}, state: "size", reply: "Which size?", handled: true},
+				{in: Input{Text: "small"}, state: "address", reply: "Deliver a small pizza where?", handled: true},
+				{in: Input{Text: "same as last time"}, reply: "A small pizza to last time", handled: true},
+			},
+		},
+		{
+			name: "transition before slot",
+			turns: []turn{
+				{in: Input{Text: "order"}, state: "size", reply: "Which size?", handled: true},
+				{in: Input{Text: "cancel"}, reply: "Cancelled", handled: true},
+			},
+		},
+		{
+			name: "postback",
+			turns: []turn{
+				{in: Input{Postback: "action=menu"}, reply: "Menu", handled: true},
+			},
+		},
+		{
+			name: "postback without slot",
+			turns: []turn{
+				{in: Input{Text: "order"}, state: "size", reply: "Which size?", handled: true},
+				{in: Input{Postback: "action=other"}, state: "size"},
+			},
+		},
+		{
+			name: "timeout",
+			turns: []turn{
+				{in: Input{Text: "order"}, state: "size", reply: "Which size?", handled: true},
+				{in: Input{Text: "large"}, at: 9 * time.Minute, state: "address", reply: "Deliver a large pizza where?", handled: true},
+				// The conversation expired, so it starts over.
+				{in: Input{Text: "Taipei 101"}, at: 20 * time.Minute, state: "start"},
+				{in: Input{Text: "order"}, at: 21 * time.Minute, state: "size", reply: "Which size?", handled: true},
+			},
+		},
+	}
+
+	start := time.Date(2019, 4, 1, 12, 0, 0, 0, time.UTC)
+	for _, test := range tests {
+		m := newPizzaMachine(t)
+		var conv *Conversation
+		for i, turn := range test.turns {
+			result := m.Step(conv, turn.in, start.Add(turn.at))
+
+			state := ""
+			if result.Conversation != nil {
+				state = result.Conversation.State
+			}
+			if state != turn.state || replyText(result.Reply) != turn.reply || result.Handled != turn.handled {
+				t.Errorf("%s: turn %d: got state %q, reply %q and handled %v, want %q, %q and %v", test.name, i,
+					state, replyText(result.Reply), result.Handled, turn.state, turn.reply, turn.handled)
+			}
+			conv = result.Conversation
+		}
+	}
+}
+
+func TestStepOtherDialog(t *testing.T) {
+	m := newPizzaMachine(t)
+	now := time.Now()
+	conv := &Conversation{Dialog: "weather", State: "size", UpdatedAt: now}
+
+	// The state of another dialog doesn't fill the size slot.
+	if result := m.Step(conv, Input{Text: "large"}, now); result.Handled || result.Conversation.State != "start" {
+		t.Errorf("got %+v, want the dialog to start over", result)
+	}
+	conv = &Conversation{Dialog: "pizza", State: "deleted", UpdatedAt: now}
+	if result := m.Step(conv, Input{Text: "order"}, now); result.Conversation == nil || result.Conversation.State != "size" {
+		t.Errorf("got %+v, want a removed state to start over", result)
+	}
+}
+
+func TestNewMachineInvalid(t *testing.T) {
+	_, err := NewMachine("broken", &linev1beta1.DialogSpec{
+		States: []linev1beta1.DialogState{{Name: "start", Slot: &linev1beta1.DialogSlot{Name: "size", Next: "missing"}}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Errorf("got error %v, want the missing state", err)
+	}
+}
+
+// failingStore fails all the calls.
+type failingStore struct{}
+
+func (failingStore) Get(source string) (*Conversation, error)    { return nil, errors.New("unavailable") }
+func (failingStore) Put(source string, conv *Conversation) error { return errors.New("unavailable") }
+func (failingStore) Delete(source string) error                  { return errors.New("unavailable") }
+
+func TestConverse(t *testing.T) {
+	type message struct {
+		source  string
+		text    string
+		handled bool
+		reply   string
+		dialog  string
+	}
+	tests := []struct {
+		name     string
+		machines func(t *testing.T) []*Machine
+		messages []message
+	}{
+		{
+			name: "unhandled",
+			machines: func(t *testing.T) []*Machine {
+				return []*Machine{newPizzaMachine(t), newWeatherMachine(t, "weather")}
+			},
+			messages: []message{{source: "alice", text: "hello"}},
+		},
+		{
+			name: "ongoing dialog first",
+			machines: func(t *testing.T) []*Machine {
+				return []*Machine{newWeatherMachine(t, "weather"), newPizzaMachine(t)}
+			},
+			messages: []message{
+				{source: "alice", text: "order", handled: true, reply: "Which size?", dialog: "pizza"},
+				// The pizza dialog expects a size, even though weather starts a dialog.
+				{source: "alice", text: "weather", handled: true, reply: "Small or large?", dialog: "pizza"},
+				{source: "bob", text: "weather", handled: true, reply: "Which city?", dialog: "weather"},
+				{source: "alice", text: "large", handled: true, reply: "Deliver a large pizza where?", dialog: "pizza"},
+				{source: "bob", text: "Taipei", handled: true, reply: "Sunny in Taipei"},
+				{source: "bob", text: "Taipei"},
+			},
+		},
+		{
+			name: "first machine wins",
+			machines: func(t *testing.T) []*Machine {
+				return []*Machine{newWeatherMachine(t, "order"), newPizzaMachine(t)}
+			},
+			messages: []message{{source: "alice", text: "order", handled: true, reply: "Which city?", dialog: "weather"}},
+		},
+	}
+
+	now := time.Now()
+	for _, test := range tests {
+		store := NewMemoryStore()
+		machines := test.machines(t)
+		for i, msg := range test.messages {
+			result, err := Converse(store, machines, msg.source, Input{Text: msg.text}, now)
+			if err != nil {
+				t.Fatalf("%s: message %d: %v", test.name, i, err)
+			}
+
+			if (result != nil) != msg.handled {
+				t.Errorf("%s: message %d: got result %+v, want handled %v", test.name, i, result, msg.handled)
+			} else if result != nil && replyText(result.Reply) != msg.reply {
+				t.Errorf("%s: message %d: got reply %q, want %q", test.name, i, replyText(result.Reply), msg.reply)
+			}
+
+			conv, _ := store.Get(msg.source)
+			dialog := ""
+			if conv != nil {
+				dialog = conv.Dialog
+			}
+			if dialog != msg.dialog {
+				t.Errorf("%s: message %d: got stored dialog %q, want %q", test.name, i, dialog, msg.dialog)
+			}
+		}
+	}
+}
+
+func TestConverseStoreErrors(t *testing.T) {
+	machines := []*Machine{newPizzaMachine(t)}
+	if _, err := Converse(failingStore{}, machines, "alice", Input{Text: "order"}, time.Now()); err == nil {
+		t.Error("got no error from the store")
+	}
+
+	store := &putFailingStore{MemoryStore: NewMemoryStore()}
+	result, err := Converse(store, machines, "alice", Input{Text: "order"}, time.Now())
+	if err == nil || result == nil {
+		t.Errorf("got result %v and error %v, want the reply and the error of the store", result, err)
+	}
+}
+
+type putFailingStore struct {
+	*MemoryStore
+}
+
+func (s *putFailingStore) Put(source string, conv *Conversation) error {
+	return errors.New("unavailable")
+}
@@ -0,0 +1,176 @@
+package conversation
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+)
+
+// Store keeps the conversation of each source, i.e. a user, group or room ID.
+type Store interface {
+	// Get returns nil when the source has no conversation.
+	Get(source string) (*Conversation, error)
+	Put(source string, conv *Conversation) error
+	Delete(source string) error
+}
+
+// MemoryStore keeps the conversations in the bot process, they are lost when the
+// pod restarts and are not shared by the replicas.
+type MemoryStore struct {
+	mu            sync.Mutex
+	conversations map[string]*Conversation
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{conversations: map[string]*Conversation{}}
+}
+
+func (s *MemoryStore) Get(source string) (*Conversation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.conversations[source], nil
+}
+
+func (s *MemoryStore) Put(source string, conv *Conversation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.conversations[source] = conv
+	return nil
+}
+
+func (s *MemoryStore) Delete(source string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.conversations, source)
+	return nil
+}
+
+// ConfigMapStore keeps the conversations in a ConfigMap keyed by source, which
+// suits bots with few concurrent conversations and several replicas.
+type ConfigMapStore struct {
+	clientset kubernetes.Interface
+	namespace string
+	name      string
+}
+
+func NewConfigMapStore(clientset kubernetes.Interface, namespace, name string) *ConfigMapStore {
+	return &ConfigMapStore{clientset: clientset, namespace: namespace, name: name}
+}
+
+func (s *ConfigMapStore) Get(source string) (*Conversation, error) {
+	cm, err := s.clientset.CoreV1().ConfigMaps(s.namespace).Get(s.name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	data, ok := cm.Data[source]
+	if !ok {
+		return nil, nil
+	}
+
+	conv := &Conversation{}
+	if err := json.Unmarshal([]byte(data), conv); err != nil {
+		return nil, err
+	}
+	return conv, nil
+}
+
+func (s *ConfigMapStore) Put(source string, conv *Conversation) error {
+	data, err := json.Marshal(conv)
+	if err != nil {
+		return err
+	}
+
+	return s.update(func(cm *v1.ConfigMap) {
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		cm.Data[source] = string(data)
+	})
+}
+
+func (s *ConfigMapStore) Delete(source string) error {
+	return s.update(func(cm *v1.ConfigMap) {
+		delete(cm.Data, source)
+	})
+}
+
+// update changes the ConfigMap, creating it if needed, and retries on conflicts
+// with the other replicas.
+func (s *ConfigMapStore) update(change func(cm *v1.ConfigMap)) error {
+	configMaps := s.clientset.CoreV1().ConfigMaps(s.namespace)
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cm, err := configMaps.Get(s.name, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			cm = &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: s.name, Namespace: s.namespace}}
+			change(cm)
+			_, err = configMaps.Create(cm)
+			if errors.IsAlreadyExists(err) {
+				return errors.NewConflict(v1.Resource("configmaps"), s.name, err)
+			}
+			return err
+		}
+		if err != nil {
+			return err
+		}
+
+		change(cm)
+		_, err = configMaps.Update(cm)
+		return err
+	})
+}
+
+// RedisClient is the subset of a Redis client used by RedisStore, Get returns an
+// empty value without error for a missing key. It can be adapted from any
+// Redis-compatible client.
+type RedisClient interface {
+	Get(key string) (string, error)
+	Set(key, value string, ttl time.Duration) error
+	Del(key string) error
+}
+
+// RedisStore keeps the conversations in Redis, they expire after the TTL, e.g.
+// the timeout of the dialogs.
+type RedisStore struct {
+	client RedisClient
+	prefix string
+	ttl    time.Duration
+}
+
+func NewRedisStore(client RedisClient, prefix string, ttl time.Duration) *RedisStore {
+	return &RedisStore{client: client, prefix: prefix, ttl: ttl}
+}
+
+func (s *RedisStore) Get(source string) (*Conversation, error) {
+	data, err := s.client.Get(s.prefix + source)
+	if err != nil || data == "" {
+		return nil, err
+	}
+
+	conv := &Conversation{}
+	if err := json.Unmarshal([]byte(data), conv); err != nil {
+		return nil, err
+	}
+	return conv, nil
+}
+
+func (s *RedisStore) Put(source string, conv *Conversation) error {
+	data, err := json.Marshal(conv)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(s.prefix+source, string(data), s.ttl)
+}
+
+func (s *RedisStore) Delete(source string) error {
+	return s.client.Del(s.prefix + source)
+}
@@ -0,0 +1,165 @@
+package conversation
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// fakeRedis keeps the keys in a map and records their TTL.
+type fakeRedis struct {
+	values map[string]string
+	ttls   map[string]time.Duration
+}
+
+func newFakeRedis() *fakeRedis {
+	return &fakeRedis{values: map[string]string{}, ttls: map[string]time.Duration{}}
+}
+
+func (r *fakeRedis) Get(key string) (string, error) {
+	return r.values[key], nil
+}
+
+func (r *fakeRedis) Set(key, value string, ttl time.Duration) error {
+	r.values[key] = value
+	r.ttls[key] = ttl
+	return nil
+}
+
+func (r *fakeRedis) Del(key string) error {
+	delete(r.values, key)
+	delete(r.ttls, key)
+	return nil
+}
+
+func TestStores(t *testing.T) {
+	tests := []struct {
+		name  string
+		store Store
+	}{
+		{name: "memory", store: NewMemoryStore()},
+		{name: "configmap", store: NewConfigMapStore(kubefake.NewSimpleClientset(), "default", "test-bot-conversations")},
+		{name: "redis", store: NewRedisStore(newFakeRedis(), "test-bot:", time.Minute)},
+	}
+
+	updatedAt := time.Date(2019, 4, 1, 12, 0, 0, 0, time.UTC)
+	alice := &Conversation{Dialog: "pizza", State: "address", Slots: map[string]string{"size": "large"}, UpdatedAt: updatedAt}
+	bob := &Conversation{Dialog: "weather", State: "city", UpdatedAt: updatedAt}
+	for _, test := range tests {
+		if conv, err := test.store.Get("alice"); conv != nil || err != nil {
+			t.Errorf("%s: got %+v and %v, want no conversation", test.name, conv, err)
+		}
+		if err := test.store.Put("alice", alice); err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if err := test.store.Put("bob", bob); err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+
+		conv, err := test.store.Get("alice")
+		if err != nil || !reflect.DeepEqual(conv, alice) {
+			t.Errorf("%s: got %+v and %v, want %+v", test.name, conv, err, alice)
+		}
+
+		if err := test.store.Delete("alice"); err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if conv, err := test.store.Get("alice"); conv != nil || err != nil {
+			t.Errorf("%s: got %+v and %v after delete, want no conversation", test.name, conv, err)
+		}
+		if conv, err := test.store.Get("bob"); err != nil || conv == nil || conv.State != "city" {
+			t.Errorf("%s: got %+v and %v, want the conversation of bob", test.name, conv, err)
+		}
+	}
+}
+
+func TestRedisStore(t *testing.T) {
+	client := newFakeRedis()
+	store := NewRedisStore(client, "test-bot:", 10*time.Minute)
+	if err := store.Put("alice", &Conversation{Dialog: "pizza", State: "size"}); err != nil {
+		t.Fatal(err)
+	}
+	if client.ttls["test-bot:alice"] != 10*time.Minute {
+		t.Errorf("got keys %v, want test-bot:alice expiring after 10m", client.ttls)
+	}
+
+	client.values["test-bot:bob"] = "{"
+	if _, err := store.Get("bob"); err == nil {
+		t.Error("got no error for an invalid conversation")
+	}
+}
+
+func TestConfigMapStoreConflict(t *testing.T) {
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-bot-conversations", Namespace: "default"},
+		Data:       map[string]string{"bob": `{"dialog":"weather","state":"city"}`},
+	}
+	tests := []struct {
+		name    string
+		objects []runtime.Object
+		verb    string
+		err     error
+	}{
+		{
+			// Another replica updated the ConfigMap after the get.
+			name:    "update conflict",
+			objects: []runtime.Object{cm},
+			verb:    "update",
+			err:     errors.NewConflict(v1.Resource("configmaps"), cm.Name, nil),
+		},
+		{
+			// Another replica created the ConfigMap after the get.
+			name: "create conflict",
+			verb: "create",
+			err:  errors.NewAlreadyExists(v1.Resource("configmaps"), cm.Name),
+		},
+	}
+
+	for _, test := range tests {
+		clientset := kubefake.NewSimpleClientset(test.objects...)
+		failed := false
+		clientset.PrependReactor(test.verb, "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			if failed {
+				return false, nil, nil
+			}
+			failed = true
+			return true, nil, test.err
+		})
+
+		store := NewConfigMapStore(clientset, "default", cm.Name)
+		if err := store.Put("alice", &Conversation{Dialog: "pizza", State: "size"}); err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if !failed {
+			t.Errorf("%s: got no %s", test.name, test.verb)
+		}
+		if conv, err := store.Get("alice"); err != nil || conv == nil || conv.State != "size" {
+			t.Errorf("%s: got %+v and %v, want the retried conversation", test.name, conv, err)
+		}
+		if conv, err := store.Get("bob"); err != nil || (conv != nil) != (len(test.objects) > 0) {
+			t.Errorf("%s: got %+v and %v for bob", test.name, conv, err)
+		}
+	}
+}
+
+func TestConfigMapStoreError(t *testing.T) {
+	clientset := kubefake.NewSimpleClientset()
+	clientset.PrependReactor("update", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.NewForbidden(v1.Resource("configmaps"), "test-bot-conversations", nil)
+	})
+
+	store := NewConfigMapStore(clientset, "default", "test-bot-conversations")
+	if err := store.Put("alice", &Conversation{Dialog: "pizza", State: "size"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Put("bob", &Conversation{Dialog: "pizza", State: "size"}); !errors.IsForbidden(err) {
+		t.Errorf("got error %v, want the forbidden update", err)
+	}
+}
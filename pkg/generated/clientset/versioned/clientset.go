@@ -20,6 +20,7 @@ package versioned
 
 import (
 	linev1alpha1 "github.com/kairen/line-bot-operator/pkg/generated/clientset/versioned/typed/line/v1alpha1"
+	linev1beta1 "github.com/kairen/line-bot-operator/pkg/generated/clientset/versioned/typed/line/v1beta1"
 	discovery "k8s.io/client-go/discovery"
 	rest "k8s.io/client-go/rest"
 	flowcontrol "k8s.io/client-go/util/flowcontrol"
@@ -28,8 +29,9 @@ import (
 type Interface interface {
 	Discovery() discovery.DiscoveryInterface
 	LineV1alpha1() linev1alpha1.LineV1alpha1Interface
+	LineV1beta1() linev1beta1.LineV1beta1Interface
 	// Deprecated: please explicitly pick a version if possible.
-	Line() linev1alpha1.LineV1alpha1Interface
+	Line() linev1beta1.LineV1beta1Interface
 }
 
 // Clientset contains the clients for groups. Each group has exactly one
@@ -37,6 +39,7 @@ type Interface interface {
 type Clientset struct {
 	*discovery.DiscoveryClient
 	lineV1alpha1 *linev1alpha1.LineV1alpha1Client
+	lineV1beta1  *linev1beta1.LineV1beta1Client
 }
 
 // LineV1alpha1 retrieves the LineV1alpha1Client
@@ -44,10 +47,15 @@ func (c *Clientset) LineV1alpha1() linev1alpha1.LineV1alpha1Interface {
 	return c.lineV1alpha1
 }
 
+// LineV1beta1 retrieves the LineV1beta1Client
+func (c *Clientset) LineV1beta1() linev1beta1.LineV1beta1Interface {
+	return c.lineV1beta1
+}
+
 // Deprecated: Line retrieves the default version of LineClient.
 // Please explicitly pick a version.
-func (c *Clientset) Line() linev1alpha1.LineV1alpha1Interface {
-	return c.lineV1alpha1
+func (c *Clientset) Line() linev1beta1.LineV1beta1Interface {
+	return c.lineV1beta1
 }
 
 // Discovery retrieves the DiscoveryClient
@@ -70,6 +78,10 @@ func NewForConfig(c *rest.Config) (*Clientset, error) {
 	if err != nil {
 		return nil, err
 	}
+	cs.lineV1beta1, err = linev1beta1.NewForConfig(&configShallowCopy)
+	if err != nil {
+		return nil, err
+	}
 
 	cs.DiscoveryClient, err = discovery.NewDiscoveryClientForConfig(&configShallowCopy)
 	if err != nil {
@@ -83,6 +95,7 @@ func NewForConfig(c *rest.Config) (*Clientset, error) {
 func NewForConfigOrDie(c *rest.Config) *Clientset {
 	var cs Clientset
 	cs.lineV1alpha1 = linev1alpha1.NewForConfigOrDie(c)
+	cs.lineV1beta1 = linev1beta1.NewForConfigOrDie(c)
 
 	cs.DiscoveryClient = discovery.NewDiscoveryClientForConfigOrDie(c)
 	return &cs
@@ -92,6 +105,7 @@ func NewForConfigOrDie(c *rest.Config) *Clientset {
 func New(c rest.Interface) *Clientset {
 	var cs Clientset
 	cs.lineV1alpha1 = linev1alpha1.New(c)
+	cs.lineV1beta1 = linev1beta1.New(c)
 
 	cs.DiscoveryClient = discovery.NewDiscoveryClient(c)
 	return &cs
@@ -22,6 +22,8 @@ import (
 	clientset "github.com/kairen/line-bot-operator/pkg/generated/clientset/versioned"
 	linev1alpha1 "github.com/kairen/line-bot-operator/pkg/generated/clientset/versioned/typed/line/v1alpha1"
 	fakelinev1alpha1 "github.com/kairen/line-bot-operator/pkg/generated/clientset/versioned/typed/line/v1alpha1/fake"
+	linev1beta1 "github.com/kairen/line-bot-operator/pkg/generated/clientset/versioned/typed/line/v1beta1"
+	fakelinev1beta1 "github.com/kairen/line-bot-operator/pkg/generated/clientset/versioned/typed/line/v1beta1/fake"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/discovery"
@@ -76,7 +78,12 @@ func (c *Clientset) LineV1alpha1() linev1alpha1.LineV1alpha1Interface {
 	return &fakelinev1alpha1.FakeLineV1alpha1{Fake: &c.Fake}
 }
 
-// Line retrieves the LineV1alpha1Client
-func (c *Clientset) Line() linev1alpha1.LineV1alpha1Interface {
-	return &fakelinev1alpha1.FakeLineV1alpha1{Fake: &c.Fake}
+// LineV1beta1 retrieves the LineV1beta1Client
+func (c *Clientset) LineV1beta1() linev1beta1.LineV1beta1Interface {
+	return &fakelinev1beta1.FakeLineV1beta1{Fake: &c.Fake}
+}
+
+// Line retrieves the LineV1beta1Client
+func (c *Clientset) Line() linev1beta1.LineV1beta1Interface {
+	return &fakelinev1beta1.FakeLineV1beta1{Fake: &c.Fake}
 }
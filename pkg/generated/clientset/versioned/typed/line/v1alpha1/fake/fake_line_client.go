@@ -32,6 +32,10 @@ func (c *FakeLineV1alpha1) Bots(namespace string) v1alpha1.BotInterface {
 	return &FakeBots{c, namespace}
 }
 
+func (c *FakeLineV1alpha1) ClusterEvents() v1alpha1.ClusterEventInterface {
+	return &FakeClusterEvents{c}
+}
+
 func (c *FakeLineV1alpha1) Events(namespace string) v1alpha1.EventInterface {
 	return &FakeEvents{c, namespace}
 }
@@ -20,6 +20,8 @@ package v1alpha1
 
 type BotExpansion interface{}
 
+type ClusterEventExpansion interface{}
+
 type EventExpansion interface{}
 
 type EventBindingExpansion interface{}
@@ -28,6 +28,7 @@ import (
 type LineV1alpha1Interface interface {
 	RESTClient() rest.Interface
 	BotsGetter
+	ClusterEventsGetter
 	EventsGetter
 	EventBindingsGetter
 }
@@ -41,6 +42,10 @@ func (c *LineV1alpha1Client) Bots(namespace string) BotInterface {
 	return newBots(c, namespace)
 }
 
+func (c *LineV1alpha1Client) ClusterEvents() ClusterEventInterface {
+	return newClusterEvents(c)
+}
+
 func (c *LineV1alpha1Client) Events(namespace string) EventInterface {
 	return newEvents(c, namespace)
 }
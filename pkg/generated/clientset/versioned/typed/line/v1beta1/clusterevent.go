@@ -0,0 +1,164 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	"time"
+
+	v1beta1 "github.com/kairen/line-bot-operator/pkg/apis/line/v1beta1"
+	scheme "github.com/kairen/line-bot-operator/pkg/generated/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// ClusterEventsGetter has a method to return a ClusterEventInterface.
+// A group's client should implement this interface.
+type ClusterEventsGetter interface {
+	ClusterEvents() ClusterEventInterface
+}
+
+// ClusterEventInterface has methods to work with ClusterEvent resources.
+type ClusterEventInterface interface {
+	Create(*v1beta1.ClusterEvent) (*v1beta1.ClusterEvent, error)
+	Update(*v1beta1.ClusterEvent) (*v1beta1.ClusterEvent, error)
+	Delete(name string, options *v1.DeleteOptions) error
+	DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error
+	Get(name string, options v1.GetOptions) (*v1beta1.ClusterEvent, error)
+	List(opts v1.ListOptions) (*v1beta1.ClusterEventList, error)
+	Watch(opts v1.ListOptions) (watch.Interface, error)
+	Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1beta1.ClusterEvent, err error)
+	ClusterEventExpansion
+}
+
+// clusterEvents implements ClusterEventInterface
+type clusterEvents struct {
+	client rest.Interface
+}
+
+// newClusterEvents returns a ClusterEvents
+func newClusterEvents(c *LineV1beta1Client) *clusterEvents {
+	return &clusterEvents{
+		client: c.RESTClient(),
+	}
+}
+
+// Get takes name of the clusterEvent, and returns the corresponding clusterEvent object, and an error if there is any.
+func (c *clusterEvents) Get(name string, options v1.GetOptions) (result *v1beta1.ClusterEvent, err error) {
+	result = &v1beta1.ClusterEvent{}
+	err = c.client.Get().
+		Resource("clusterevents").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of ClusterEvents that match those selectors.
+func (c *clusterEvents) List(opts v1.ListOptions) (result *v1beta1.ClusterEventList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1beta1.ClusterEventList{}
+	err = c.client.Get().
+		Resource("clusterevents").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do().
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested clusterEvents.
+func (c *clusterEvents) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Resource("clusterevents").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch()
+}
+
+// Create takes the representation of a clusterEvent and creates it.  Returns the server's representation of the clusterEvent, and an error, if there is any.
+func (c *clusterEvents) Create(clusterEvent *v1beta1.ClusterEvent) (result *v1beta1.ClusterEvent, err error) {
+	result = &v1beta1.ClusterEvent{}
+	err = c.client.Post().
+		Resource("clusterevents").
+		Body(clusterEvent).
+		Do().
+		Into(result)
+	return
+}
+
+// Update takes the representation of a clusterEvent and updates it. Returns the server's representation of the clusterEvent, and an error, if there is any.
+func (c *clusterEvents) Update(clusterEvent *v1beta1.ClusterEvent) (result *v1beta1.ClusterEvent, err error) {
+	result = &v1beta1.ClusterEvent{}
+	err = c.client.Put().
+		Resource("clusterevents").
+		Name(clusterEvent.Name).
+		Body(clusterEvent).
+		Do().
+		Into(result)
+	return
+}
+
+// Delete takes name of the clusterEvent and deletes it. Returns an error if one occurs.
+func (c *clusterEvents) Delete(name string, options *v1.DeleteOptions) error {
+	return c.client.Delete().
+		Resource("clusterevents").
+		Name(name).
+		Body(options).
+		Do().
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *clusterEvents) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	var timeout time.Duration
+	if listOptions.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOptions.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Resource("clusterevents").
+		VersionedParams(&listOptions, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(options).
+		Do().
+		Error()
+}
+
+// Patch applies the patch and returns the patched clusterEvent.
+func (c *clusterEvents) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1beta1.ClusterEvent, err error) {
+	result = &v1beta1.ClusterEvent{}
+	err = c.client.Patch(pt).
+		Resource("clusterevents").
+		SubResource(subresources...).
+		Name(name).
+		Body(data).
+		Do().
+		Into(result)
+	return
+}
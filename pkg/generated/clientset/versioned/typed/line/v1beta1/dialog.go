@@ -0,0 +1,174 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	"time"
+
+	v1beta1 "github.com/kairen/line-bot-operator/pkg/apis/line/v1beta1"
+	scheme "github.com/kairen/line-bot-operator/pkg/generated/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// DialogsGetter has a method to return a DialogInterface.
+// A group's client should implement this interface.
+type DialogsGetter interface {
+	Dialogs(namespace string) DialogInterface
+}
+
+// DialogInterface has methods to work with Dialog resources.
+type DialogInterface interface {
+	Create(*v1beta1.Dialog) (*v1beta1.Dialog, error)
+	Update(*v1beta1.Dialog) (*v1beta1.Dialog, error)
+	Delete(name string, options *v1.DeleteOptions) error
+	DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error
+	Get(name string, options v1.GetOptions) (*v1beta1.Dialog, error)
+	List(opts v1.ListOptions) (*v1beta1.DialogList, error)
+	Watch(opts v1.ListOptions) (watch.Interface, error)
+	Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1beta1.Dialog, err error)
+	DialogExpansion
+}
+
+// dialogs implements DialogInterface
+type dialogs struct {
+	client rest.Interface
+	ns     string
+}
+
+// newDialogs returns a Dialogs
+func newDialogs(c *LineV1beta1Client, namespace string) *dialogs {
+	return &dialogs{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the dialog, and returns the corresponding dialog object, and an error if there is any.
+func (c *dialogs) Get(name string, options v1.GetOptions) (result *v1beta1.Dialog, err error) {
+	result = &v1beta1.Dialog{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("dialogs").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of Dialogs that match those selectors.
+func (c *dialogs) List(opts v1.ListOptions) (result *v1beta1.DialogList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1beta1.DialogList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("dialogs").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do().
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested dialogs.
+func (c *dialogs) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("dialogs").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch()
+}
+
+// Create takes the representation of a dialog and creates it.  Returns the server's representation of the dialog, and an error, if there is any.
+func (c *dialogs) Create(dialog *v1beta1.Dialog) (result *v1beta1.Dialog, err error) {
+	result = &v1beta1.Dialog{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("dialogs").
+		Body(dialog).
+		Do().
+		Into(result)
+	return
+}
+
+// Update takes the representation of a dialog and updates it. Returns the server's representation of the dialog, and an error, if there is any.
+func (c *dialogs) Update(dialog *v1beta1.Dialog) (result *v1beta1.Dialog, err error) {
+	result = &v1beta1.Dialog{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("dialogs").
+		Name(dialog.Name).
+		Body(dialog).
+		Do().
+		Into(result)
+	return
+}
+
+// Delete takes name of the dialog and deletes it. Returns an error if one occurs.
+func (c *dialogs) Delete(name string, options *v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("dialogs").
+		Name(name).
+		Body(options).
+		Do().
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *dialogs) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	var timeout time.Duration
+	if listOptions.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOptions.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("dialogs").
+		VersionedParams(&listOptions, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(options).
+		Do().
+		Error()
+}
+
+// Patch applies the patch and returns the patched dialog.
+func (c *dialogs) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1beta1.Dialog, err error) {
+	result = &v1beta1.Dialog{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("dialogs").
+		SubResource(subresources...).
+		Name(name).
+		Body(data).
+		Do().
+		Into(result)
+	return
+}
@@ -0,0 +1,140 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	v1beta1 "github.com/kairen/line-bot-operator/pkg/apis/line/v1beta1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeBots implements BotInterface
+type FakeBots struct {
+	Fake *FakeLineV1beta1
+	ns   string
+}
+
+var botsResource = schema.GroupVersionResource{Group: "line.you", Version: "v1beta1", Resource: "bots"}
+
+var botsKind = schema.GroupVersionKind{Group: "line.you", Version: "v1beta1", Kind: "Bot"}
+
+// Get takes name of the bot, and returns the corresponding bot object, and an error if there is any.
+func (c *FakeBots) Get(name string, options v1.GetOptions) (result *v1beta1.Bot, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(botsResource, c.ns, name), &v1beta1.Bot{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.Bot), err
+}
+
+// List takes label and field selectors, and returns the list of Bots that match those selectors.
+func (c *FakeBots) List(opts v1.ListOptions) (result *v1beta1.BotList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(botsResource, botsKind, c.ns, opts), &v1beta1.BotList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1beta1.BotList{ListMeta: obj.(*v1beta1.BotList).ListMeta}
+	for _, item := range obj.(*v1beta1.BotList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested bots.
+func (c *FakeBots) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(botsResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a bot and creates it.  Returns the server's representation of the bot, and an error, if there is any.
+func (c *FakeBots) Create(bot *v1beta1.Bot) (result *v1beta1.Bot, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(botsResource, c.ns, bot), &v1beta1.Bot{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.Bot), err
+}
+
+// Update takes the representation of a bot and updates it. Returns the server's representation of the bot, and an error, if there is any.
+func (c *FakeBots) Update(bot *v1beta1.Bot) (result *v1beta1.Bot, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(botsResource, c.ns, bot), &v1beta1.Bot{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.Bot), err
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeBots) UpdateStatus(bot *v1beta1.Bot) (*v1beta1.Bot, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(botsResource, "status", c.ns, bot), &v1beta1.Bot{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.Bot), err
+}
+
+// Delete takes name of the bot and deletes it. Returns an error if one occurs.
+func (c *FakeBots) Delete(name string, options *v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteAction(botsResource, c.ns, name), &v1beta1.Bot{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeBots) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(botsResource, c.ns, listOptions)
+
+	_, err := c.Fake.Invokes(action, &v1beta1.BotList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched bot.
+func (c *FakeBots) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1beta1.Bot, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(botsResource, c.ns, name, pt, data, subresources...), &v1beta1.Bot{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.Bot), err
+}
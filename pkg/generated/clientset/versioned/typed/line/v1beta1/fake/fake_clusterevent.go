@@ -0,0 +1,120 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	v1beta1 "github.com/kairen/line-bot-operator/pkg/apis/line/v1beta1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeClusterEvents implements ClusterEventInterface
+type FakeClusterEvents struct {
+	Fake *FakeLineV1beta1
+}
+
+var clustereventsResource = schema.GroupVersionResource{Group: "line.you", Version: "v1beta1", Resource: "clusterevents"}
+
+var clustereventsKind = schema.GroupVersionKind{Group: "line.you", Version: "v1beta1", Kind: "ClusterEvent"}
+
+// Get takes name of the clusterEvent, and returns the corresponding clusterEvent object, and an error if there is any.
+func (c *FakeClusterEvents) Get(name string, options v1.GetOptions) (result *v1beta1.ClusterEvent, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootGetAction(clustereventsResource, name), &v1beta1.ClusterEvent{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.ClusterEvent), err
+}
+
+// List takes label and field selectors, and returns the list of ClusterEvents that match those selectors.
+func (c *FakeClusterEvents) List(opts v1.ListOptions) (result *v1beta1.ClusterEventList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootListAction(clustereventsResource, clustereventsKind, opts), &v1beta1.ClusterEventList{})
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1beta1.ClusterEventList{ListMeta: obj.(*v1beta1.ClusterEventList).ListMeta}
+	for _, item := range obj.(*v1beta1.ClusterEventList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested clusterEvents.
+func (c *FakeClusterEvents) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewRootWatchAction(clustereventsResource, opts))
+}
+
+// Create takes the representation of a clusterEvent and creates it.  Returns the server's representation of the clusterEvent, and an error, if there is any.
+func (c *FakeClusterEvents) Create(clusterEvent *v1beta1.ClusterEvent) (result *v1beta1.ClusterEvent, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootCreateAction(clustereventsResource, clusterEvent), &v1beta1.ClusterEvent{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.ClusterEvent), err
+}
+
+// Update takes the representation of a clusterEvent and updates it. Returns the server's representation of the clusterEvent, and an error, if there is any.
+func (c *FakeClusterEvents) Update(clusterEvent *v1beta1.ClusterEvent) (result *v1beta1.ClusterEvent, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootUpdateAction(clustereventsResource, clusterEvent), &v1beta1.ClusterEvent{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.ClusterEvent), err
+}
+
+// Delete takes name of the clusterEvent and deletes it. Returns an error if one occurs.
+func (c *FakeClusterEvents) Delete(name string, options *v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewRootDeleteAction(clustereventsResource, name), &v1beta1.ClusterEvent{})
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeClusterEvents) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	action := testing.NewRootDeleteCollectionAction(clustereventsResource, listOptions)
+
+	_, err := c.Fake.Invokes(action, &v1beta1.ClusterEventList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched clusterEvent.
+func (c *FakeClusterEvents) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1beta1.ClusterEvent, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootPatchSubresourceAction(clustereventsResource, name, pt, data, subresources...), &v1beta1.ClusterEvent{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.ClusterEvent), err
+}
@@ -0,0 +1,128 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	v1beta1 "github.com/kairen/line-bot-operator/pkg/apis/line/v1beta1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeDialogs implements DialogInterface
+type FakeDialogs struct {
+	Fake *FakeLineV1beta1
+	ns   string
+}
+
+var dialogsResource = schema.GroupVersionResource{Group: "line.you", Version: "v1beta1", Resource: "dialogs"}
+
+var dialogsKind = schema.GroupVersionKind{Group: "line.you", Version: "v1beta1", Kind: "Dialog"}
+
+// Get takes name of the dialog, and returns the corresponding dialog object, and an error if there is any.
+func (c *FakeDialogs) Get(name string, options v1.GetOptions) (result *v1beta1.Dialog, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(dialogsResource, c.ns, name), &v1beta1.Dialog{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.Dialog), err
+}
+
+// List takes label and field selectors, and returns the list of Dialogs that match those selectors.
+func (c *FakeDialogs) List(opts v1.ListOptions) (result *v1beta1.DialogList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(dialogsResource, dialogsKind, c.ns, opts), &v1beta1.DialogList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1beta1.DialogList{ListMeta: obj.(*v1beta1.DialogList).ListMeta}
+	for _, item := range obj.(*v1beta1.DialogList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested dialogs.
+func (c *FakeDialogs) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(dialogsResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a dialog and creates it.  Returns the server's representation of the dialog, and an error, if there is any.
+func (c *FakeDialogs) Create(dialog *v1beta1.Dialog) (result *v1beta1.Dialog, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(dialogsResource, c.ns, dialog), &v1beta1.Dialog{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.Dialog), err
+}
+
+// Update takes the representation of a dialog and updates it. Returns the server's representation of the dialog, and an error, if there is any.
+func (c *FakeDialogs) Update(dialog *v1beta1.Dialog) (result *v1beta1.Dialog, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(dialogsResource, c.ns, dialog), &v1beta1.Dialog{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.Dialog), err
+}
+
+// Delete takes name of the dialog and deletes it. Returns an error if one occurs.
+func (c *FakeDialogs) Delete(name string, options *v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteAction(dialogsResource, c.ns, name), &v1beta1.Dialog{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeDialogs) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(dialogsResource, c.ns, listOptions)
+
+	_, err := c.Fake.Invokes(action, &v1beta1.DialogList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched dialog.
+func (c *FakeDialogs) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1beta1.Dialog, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(dialogsResource, c.ns, name, pt, data, subresources...), &v1beta1.Dialog{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.Dialog), err
+}
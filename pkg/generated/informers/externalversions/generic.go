@@ -22,6 +22,7 @@ import (
 	"fmt"
 
 	v1alpha1 "github.com/kairen/line-bot-operator/pkg/apis/line/v1alpha1"
+	v1beta1 "github.com/kairen/line-bot-operator/pkg/apis/line/v1beta1"
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
 	cache "k8s.io/client-go/tools/cache"
 )
@@ -55,11 +56,25 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 	// Group=line.you, Version=v1alpha1
 	case v1alpha1.SchemeGroupVersion.WithResource("bots"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Line().V1alpha1().Bots().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("clusterevents"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Line().V1alpha1().ClusterEvents().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("events"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Line().V1alpha1().Events().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("eventbindings"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Line().V1alpha1().EventBindings().Informer()}, nil
 
+		// Group=line.you, Version=v1beta1
+	case v1beta1.SchemeGroupVersion.WithResource("bots"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Line().V1beta1().Bots().Informer()}, nil
+	case v1beta1.SchemeGroupVersion.WithResource("clusterevents"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Line().V1beta1().ClusterEvents().Informer()}, nil
+	case v1beta1.SchemeGroupVersion.WithResource("dialogs"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Line().V1beta1().Dialogs().Informer()}, nil
+	case v1beta1.SchemeGroupVersion.WithResource("events"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Line().V1beta1().Events().Informer()}, nil
+	case v1beta1.SchemeGroupVersion.WithResource("eventbindings"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Line().V1beta1().EventBindings().Informer()}, nil
+
 	}
 
 	return nil, fmt.Errorf("no informer found for %v", resource)
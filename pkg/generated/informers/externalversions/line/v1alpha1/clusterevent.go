@@ -0,0 +1,88 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by informer-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	time "time"
+
+	linev1alpha1 "github.com/kairen/line-bot-operator/pkg/apis/line/v1alpha1"
+	versioned "github.com/kairen/line-bot-operator/pkg/generated/clientset/versioned"
+	internalinterfaces "github.com/kairen/line-bot-operator/pkg/generated/informers/externalversions/internalinterfaces"
+	v1alpha1 "github.com/kairen/line-bot-operator/pkg/generated/listers/line/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	watch "k8s.io/apimachinery/pkg/watch"
+	cache "k8s.io/client-go/tools/cache"
+)
+
+// ClusterEventInformer provides access to a shared informer and lister for
+// ClusterEvents.
+type ClusterEventInformer interface {
+	Informer() cache.SharedIndexInformer
+	Lister() v1alpha1.ClusterEventLister
+}
+
+type clusterEventInformer struct {
+	factory          internalinterfaces.SharedInformerFactory
+	tweakListOptions internalinterfaces.TweakListOptionsFunc
+}
+
+// NewClusterEventInformer constructs a new informer for ClusterEvent type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewClusterEventInformer(client versioned.Interface, resyncPeriod time.Duration, indexers cache.Indexers) cache.SharedIndexInformer {
+	return NewFilteredClusterEventInformer(client, resyncPeriod, indexers, nil)
+}
+
+// NewFilteredClusterEventInformer constructs a new informer for ClusterEvent type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewFilteredClusterEventInformer(client versioned.Interface, resyncPeriod time.Duration, indexers cache.Indexers, tweakListOptions internalinterfaces.TweakListOptionsFunc) cache.SharedIndexInformer {
+	return cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options v1.ListOptions) (runtime.Object, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.LineV1alpha1().ClusterEvents().List(options)
+			},
+			WatchFunc: func(options v1.ListOptions) (watch.Interface, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.LineV1alpha1().ClusterEvents().Watch(options)
+			},
+		},
+		&linev1alpha1.ClusterEvent{},
+		resyncPeriod,
+		indexers,
+	)
+}
+
+func (f *clusterEventInformer) defaultInformer(client versioned.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	return NewFilteredClusterEventInformer(client, resyncPeriod, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, f.tweakListOptions)
+}
+
+func (f *clusterEventInformer) Informer() cache.SharedIndexInformer {
+	return f.factory.InformerFor(&linev1alpha1.ClusterEvent{}, f.defaultInformer)
+}
+
+func (f *clusterEventInformer) Lister() v1alpha1.ClusterEventLister {
+	return v1alpha1.NewClusterEventLister(f.Informer().GetIndexer())
+}
@@ -26,6 +26,8 @@ import (
 type Interface interface {
 	// Bots returns a BotInformer.
 	Bots() BotInformer
+	// ClusterEvents returns a ClusterEventInformer.
+	ClusterEvents() ClusterEventInformer
 	// Events returns a EventInformer.
 	Events() EventInformer
 	// EventBindings returns a EventBindingInformer.
@@ -48,6 +50,11 @@ func (v *version) Bots() BotInformer {
 	return &botInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// ClusterEvents returns a ClusterEventInformer.
+func (v *version) ClusterEvents() ClusterEventInformer {
+	return &clusterEventInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
 // Events returns a EventInformer.
 func (v *version) Events() EventInformer {
 	return &eventInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
@@ -0,0 +1,73 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by informer-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	internalinterfaces "github.com/kairen/line-bot-operator/pkg/generated/informers/externalversions/internalinterfaces"
+)
+
+// Interface provides access to all the informers in this group version.
+type Interface interface {
+	// Bots returns a BotInformer.
+	Bots() BotInformer
+	// ClusterEvents returns a ClusterEventInformer.
+	ClusterEvents() ClusterEventInformer
+	// Dialogs returns a DialogInformer.
+	Dialogs() DialogInformer
+	// Events returns a EventInformer.
+	Events() EventInformer
+	// EventBindings returns a EventBindingInformer.
+	EventBindings() EventBindingInformer
+}
+
+type version struct {
+	factory          internalinterfaces.SharedInformerFactory
+	namespace        string
+	tweakListOptions internalinterfaces.TweakListOptionsFunc
+}
+
+// New returns a new Interface.
+func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakListOptions internalinterfaces.TweakListOptionsFunc) Interface {
+	return &version{factory: f, namespace: namespace, tweakListOptions: tweakListOptions}
+}
+
+// Bots returns a BotInformer.
+func (v *version) Bots() BotInformer {
+	return &botInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// ClusterEvents returns a ClusterEventInformer.
+func (v *version) ClusterEvents() ClusterEventInformer {
+	return &clusterEventInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
+// Dialogs returns a DialogInformer.
+func (v *version) Dialogs() DialogInformer {
+	return &dialogInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// Events returns a EventInformer.
+func (v *version) Events() EventInformer {
+	return &eventInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// EventBindings returns a EventBindingInformer.
+func (v *version) EventBindings() EventBindingInformer {
+	return &eventBindingInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
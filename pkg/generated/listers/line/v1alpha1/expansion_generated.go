@@ -26,6 +26,10 @@ type BotListerExpansion interface{}
 // BotNamespaceLister.
 type BotNamespaceListerExpansion interface{}
 
+// ClusterEventListerExpansion allows custom methods to be added to
+// ClusterEventLister.
+type ClusterEventListerExpansion interface{}
+
 // EventListerExpansion allows custom methods to be added to
 // EventLister.
 type EventListerExpansion interface{}
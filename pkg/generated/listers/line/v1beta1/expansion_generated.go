@@ -0,0 +1,55 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1beta1
+
+// BotListerExpansion allows custom methods to be added to
+// BotLister.
+type BotListerExpansion interface{}
+
+// BotNamespaceListerExpansion allows custom methods to be added to
+// BotNamespaceLister.
+type BotNamespaceListerExpansion interface{}
+
+// ClusterEventListerExpansion allows custom methods to be added to
+// ClusterEventLister.
+type ClusterEventListerExpansion interface{}
+
+// DialogListerExpansion allows custom methods to be added to
+// DialogLister.
+type DialogListerExpansion interface{}
+
+// DialogNamespaceListerExpansion allows custom methods to be added to
+// DialogNamespaceLister.
+type DialogNamespaceListerExpansion interface{}
+
+// EventListerExpansion allows custom methods to be added to
+// EventLister.
+type EventListerExpansion interface{}
+
+// EventNamespaceListerExpansion allows custom methods to be added to
+// EventNamespaceLister.
+type EventNamespaceListerExpansion interface{}
+
+// EventBindingListerExpansion allows custom methods to be added to
+// EventBindingLister.
+type EventBindingListerExpansion interface{}
+
+// EventBindingNamespaceListerExpansion allows custom methods to be added to
+// EventBindingNamespaceLister.
+type EventBindingNamespaceListerExpansion interface{}
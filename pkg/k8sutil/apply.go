@@ -0,0 +1,117 @@
+package k8sutil
+
+import (
+	"encoding/json"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/jsonmergepatch"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+)
+
+const (
+	// FieldManager is recorded in the managed-by label of the applied objects.
+	FieldManager   = "line-bot-operator"
+	ManagedByLabel = "app.kubernetes.io/managed-by"
+
+	// LastAppliedAnnotation records the object last applied by the operator, the same
+	// way as kubectl apply, to find the fields to remove on the next apply.
+	LastAppliedAnnotation = "line.you/last-applied-configuration"
+)
+
+// SetLastApplied labels the desired object as managed by the operator and records
+// it in the last applied annotation.
+func SetLastApplied(desired runtime.Object) error {
+	accessor, err := meta.Accessor(desired)
+	if err != nil {
+		return err
+	}
+
+	labels := accessor.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[ManagedByLabel] = FieldManager
+	accessor.SetLabels(labels)
+
+	annotations := accessor.GetAnnotations()
+	delete(annotations, LastAppliedAnnotation)
+	accessor.SetAnnotations(annotations)
+
+	applied, err := applyJSON(desired)
+	if err != nil {
+		return err
+	}
+
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[LastAppliedAnnotation] = string(applied)
+	accessor.SetAnnotations(annotations)
+	return nil
+}
+
+// CreateApplyPatch returns the three-way patch from current to the desired object,
+// which has to be prepared by SetLastApplied, or nil when current is up to date.
+// The fields set by others, e.g. the status or the defaults, are kept. Custom
+// resources don't support strategic merge patches, so they use JSON merge patches.
+func CreateApplyPatch(current, desired runtime.Object, patchType types.PatchType) ([]byte, error) {
+	currentAccessor, err := meta.Accessor(current)
+	if err != nil {
+		return nil, err
+	}
+	original := []byte(currentAccessor.GetAnnotations()[LastAppliedAnnotation])
+
+	modified, err := applyJSON(desired)
+	if err != nil {
+		return nil, err
+	}
+
+	currentJSON, err := json.Marshal(current)
+	if err != nil {
+		return nil, err
+	}
+
+	var patch []byte
+	if patchType == types.StrategicMergePatchType {
+		schema, err := strategicpatch.NewPatchMetaFromStruct(desired)
+		if err != nil {
+			return nil, err
+		}
+		patch, err = strategicpatch.CreateThreeWayMergePatch(original, modified, currentJSON, schema, true)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		patch, err = jsonmergepatch.CreateThreeWayJSONMergePatch(original, modified, currentJSON)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if string(patch) == "{}" {
+		return nil, nil
+	}
+	return patch, nil
+}
+
+// applyJSON marshals an object without the fields that are never applied, i.e.
+// the status and the creation timestamp serialized as null.
+func applyJSON(obj runtime.Object) ([]byte, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	delete(fields, "status")
+	if metadata, ok := fields["metadata"].(map[string]interface{}); ok {
+		delete(metadata, "creationTimestamp")
+	}
+	return json.Marshal(fields)
+}
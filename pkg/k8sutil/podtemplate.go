@@ -0,0 +1,70 @@
+package k8sutil
+
+import (
+	"encoding/json"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+)
+
+// MergePodTemplate merges the overlay into the pod template like a strategic merge
+// patch. Unset fields of the overlay are ignored rather than deleting the template fields.
+func MergePodTemplate(template *v1.PodTemplateSpec, overlay *v1.PodTemplateSpec) (*v1.PodTemplateSpec, error) {
+	if overlay == nil {
+		return template, nil
+	}
+
+	original, err := json.Marshal(template)
+	if err != nil {
+		return nil, err
+	}
+
+	patch, err := toPatch(overlay)
+	if err != nil {
+		return nil, err
+	}
+
+	merged, err := strategicpatch.StrategicMergePatch(original, patch, v1.PodTemplateSpec{})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &v1.PodTemplateSpec{}
+	if err := json.Unmarshal(merged, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// toPatch converts the overlay to a patch without the null fields, since a null in
+// a strategic merge patch deletes the field.
+func toPatch(overlay *v1.PodTemplateSpec) ([]byte, error) {
+	data, err := json.Marshal(overlay)
+	if err != nil {
+		return nil, err
+	}
+
+	patch := map[string]interface{}{}
+	if err := json.Unmarshal(data, &patch); err != nil {
+		return nil, err
+	}
+	return json.Marshal(removeNulls(patch))
+}
+
+func removeNulls(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if item == nil {
+				delete(v, key)
+				continue
+			}
+			v[key] = removeNulls(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = removeNulls(item)
+		}
+	}
+	return value
+}
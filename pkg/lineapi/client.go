@@ -0,0 +1,136 @@
+package lineapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/kairen/line-bot-operator/pkg/channeltoken"
+)
+
+const (
+	quotaPath       = "/v2/bot/message/quota"
+	consumptionPath = "/v2/bot/message/quota/consumption"
+	followersPath   = "/v2/bot/insight/followers"
+	deliveryPath    = "/v2/bot/insight/message/delivery"
+
+	// QuotaLimited is the quota type of the plans with a monthly message limit.
+	QuotaLimited = "limited"
+	// InsightReady is the status of the insights that are computed.
+	InsightReady = "ready"
+)
+
+// The insights are computed per day in Japan time.
+var insightLocation = time.FixedZone("JST", 9*60*60)
+
+// Quota is the target limit of messages of the current month.
+type Quota struct {
+	Type  string `json:"type"`
+	Value int64  `json:"value"`
+}
+
+// Followers are the statistics of the friends of a bot on a day.
+type Followers struct {
+	Status          string `json:"status"`
+	Followers       int64  `json:"followers"`
+	TargetedReaches int64  `json:"targetedReaches"`
+	Blocks          int64  `json:"blocks"`
+}
+
+// Delivery is the number of messages sent by a bot on a day, by kind.
+type Delivery struct {
+	Status       string `json:"status"`
+	APIReply     int64  `json:"apiReply"`
+	APIPush      int64  `json:"apiPush"`
+	APIMulticast int64  `json:"apiMulticast"`
+	APIBroadcast int64  `json:"apiBroadcast"`
+}
+
+// Client reads the quota and the insights of a bot from the Messaging API.
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+func NewClient() *Client {
+	return &Client{
+		BaseURL:    channeltoken.DefaultAPIBaseURL,
+		HTTPClient: &http.Client{Timeout: channeltoken.DefaultTimeout},
+	}
+}
+
+func (c *Client) GetQuota(token string) (*Quota, error) {
+	quota := &Quota{}
+	if err := c.get(token, quotaPath, nil, quota); err != nil {
+		return nil, err
+	}
+	return quota, nil
+}
+
+// GetConsumption returns the number of messages sent in the current month.
+func (c *Client) GetConsumption(token string) (int64, error) {
+	resp := struct {
+		TotalUsage int64 `json:"totalUsage"`
+	}{}
+	if err := c.get(token, consumptionPath, nil, &resp); err != nil {
+		return 0, err
+	}
+	return resp.TotalUsage, nil
+}
+
+// GetFollowers returns the statistics of a day, see InsightDate.
+func (c *Client) GetFollowers(token, date string) (*Followers, error) {
+	followers := &Followers{}
+	if err := c.get(token, followersPath, url.Values{"date": {date}}, followers); err != nil {
+		return nil, err
+	}
+	return followers, nil
+}
+
+// GetDelivery returns the number of messages sent on a day, see InsightDate.
+func (c *Client) GetDelivery(token, date string) (*Delivery, error) {
+	delivery := &Delivery{}
+	if err := c.get(token, deliveryPath, url.Values{"date": {date}}, delivery); err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// InsightDate returns the date of the day before t as yyyyMMdd, the last day
+// with insights.
+func InsightDate(t time.Time) string {
+	return t.In(insightLocation).AddDate(0, 0, -1).Format("20060102")
+}
+
+func (c *Client) get(token, path string, query url.Values, out interface{}) error {
+	u := strings.TrimSuffix(c.BaseURL, "/") + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s: %s", path, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return json.Unmarshal(body, out)
+}
@@ -0,0 +1,30 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"k8s.io/klog"
+)
+
+// Serve serves the metrics of the default Prometheus registry on /metrics until
+// stopCh is closed.
+func Serve(addr string, stopCh chan struct{}) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	server := &http.Server{Addr: addr, Handler: mux}
+
+	go func() {
+		klog.Infof("Start serving metrics on %s.", addr)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			klog.Errorf("Failed to serve metrics: %+v.", err)
+		}
+	}()
+
+	go func() {
+		<-stopCh
+		if err := server.Close(); err != nil {
+			klog.Errorf("Failed to shutdown metrics: %+v.", err)
+		}
+	}()
+}
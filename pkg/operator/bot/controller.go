@@ -2,14 +2,21 @@ package bot
 
 import (
 	"reflect"
+	"sync"
 	"time"
 
 	linev1alpha1 "github.com/kairen/line-bot-operator/pkg/apis/line/v1alpha1"
+	"github.com/kairen/line-bot-operator/pkg/config"
+	"github.com/kairen/line-bot-operator/pkg/constants"
 	clientset "github.com/kairen/line-bot-operator/pkg/generated/clientset/versioned"
 	opkit "github.com/kubedev/operator-kit"
 	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog"
 )
 
@@ -30,10 +37,24 @@ var Resource = opkit.CustomResource{
 type Controller struct {
 	ctx       *opkit.Context
 	clientset clientset.Interface
+	recorder  record.EventRecorder
+	config    *config.Store
+	queue     workqueue.RateLimitingInterface
+	// bots caches the bots by channel secret, see watchBots.
+	bots cache.Indexer
+	// secretWatches stops the watch of each channel secret, see syncSecretWatches.
+	secretsMu     sync.Mutex
+	secretWatches map[string]chan struct{}
 }
 
-func NewController(ctx *opkit.Context, clientset clientset.Interface) *Controller {
-	return &Controller{ctx: ctx, clientset: clientset}
+func NewController(ctx *opkit.Context, clientset clientset.Interface, recorder record.EventRecorder, config *config.Store) *Controller {
+	return &Controller{
+		ctx:       ctx,
+		clientset: clientset,
+		recorder:  recorder,
+		config:    config,
+		queue:     workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), customResourceNamePlural),
+	}
 }
 
 func (c *Controller) StartWatch(namespace string, stopCh chan struct{}) error {
@@ -46,54 +67,158 @@ func (c *Controller) StartWatch(namespace string, stopCh chan struct{}) error {
 	klog.Infof("Start watching bot resources.")
 	watcher := opkit.NewWatcher(Resource, namespace, resourceHandlerFuncs, c.clientset.LineV1alpha1().RESTClient())
 	go watcher.Watch(&linev1alpha1.Bot{}, stopCh)
+	go c.runWorker(stopCh)
+	go c.resync(namespace, stopCh)
+	c.watchBots(namespace, stopCh)
+	c.watchOwned(namespace, stopCh)
 	return nil
 }
 
-func (c *Controller) onAdd(obj interface{}) {
-	bot := obj.(*linev1alpha1.Bot).DeepCopy()
-	klog.V(2).Infof("Received onAdd on Bot %s in %s namespace.", bot.Name, bot.Namespace)
+func (c *Controller) enqueue(bot *linev1alpha1.Bot) {
+	key, err := cache.MetaNamespaceKeyFunc(bot)
+	if err != nil {
+		klog.Errorf("Failed to get the key of Bot %s: %+v.", bot.Name, err)
+		return
+	}
+	c.queue.Add(key)
+}
+
+// runWorker syncs the queued bots one by one, so that the watch events, the resync
+// and the owned resources never sync the same bot concurrently.
+func (c *Controller) runWorker(stopCh chan struct{}) {
+	go func() {
+		<-stopCh
+		c.queue.ShutDown()
+	}()
+
+	for c.processNextItem() {
+	}
+}
+
+func (c *Controller) processNextItem() bool {
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(key)
+
+	if err := c.sync(key.(string)); err != nil {
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	c.queue.Forget(key)
+	return true
+}
+
+func (c *Controller) sync(key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+
+	bot, err := c.clientset.LineV1alpha1().Bots(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		klog.Errorf("Failed to get Bot %s in %s namespace: %+v.", name, namespace, err)
+		return err
+	}
+
+	if bot.Annotations[constants.PausedAnnotation] == "true" {
+		klog.V(2).Infof("Skipping paused Bot %s in %s namespace.", bot.Name, bot.Namespace)
+		return nil
+	}
 
 	if bot.Status.Phase == "" {
 		bot.Status.Phase = linev1alpha1.BotPending
 	}
 
+	// Default the spec in case the mutating webhook is not installed.
+	c.config.Get().SetBotDefaults(bot)
+
 	if bot.Status.Phase == linev1alpha1.BotPending || bot.Status.Phase == linev1alpha1.BotFailed {
 		if err := c.createBot(bot); err != nil {
 			klog.Errorf("Failed to create bot on %s in %s namespace: %+v.", bot.Name, bot.Namespace, err)
+			return err
 		}
+		return nil
 	}
+
+	errs := []error{}
+	if err := c.migrateNgrokConfigMap(bot); err != nil {
+		klog.Errorf("Failed to migrate ngrok configmap on %s in %s namespace: %+v.", bot.Name, bot.Namespace, err)
+		errs = append(errs, err)
+	}
+
+	if err := c.syncChannelToken(bot); err != nil {
+		klog.Errorf("Failed to sync channel token on %s in %s namespace: %+v.", bot.Name, bot.Namespace, err)
+		errs = append(errs, err)
+	}
+
+	if err := c.syncSecretHash(bot); err != nil {
+		klog.Errorf("Failed to sync channel secret on %s in %s namespace: %+v.", bot.Name, bot.Namespace, err)
+		errs = append(errs, err)
+	}
+
+	if err := c.reconcileOwned(bot); err != nil {
+		klog.Errorf("Failed to apply owned resources on %s in %s namespace: %+v.", bot.Name, bot.Namespace, err)
+		errs = append(errs, err)
+	}
+
+	if err := c.syncQuota(bot); err != nil {
+		klog.Errorf("Failed to poll quota on %s in %s namespace: %+v.", bot.Name, bot.Namespace, err)
+		errs = append(errs, err)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// resync periodically requeues all the bots, which retries the ones that are not
+// active yet. The period is read on every round, so that a reloaded config takes effect.
+func (c *Controller) resync(namespace string, stopCh chan struct{}) {
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-time.After(c.config.Get().ResyncPeriod.Duration):
+			bots, err := c.clientset.LineV1alpha1().Bots(namespace).List(metav1.ListOptions{})
+			if err != nil {
+				klog.Errorf("Failed to list bots for resync: %+v.", err)
+				continue
+			}
+			for i := range bots.Items {
+				c.enqueue(&bots.Items[i])
+			}
+		}
+	}
+}
+
+func (c *Controller) onAdd(obj interface{}) {
+	bot := obj.(*linev1alpha1.Bot)
+	klog.V(2).Infof("Received onAdd on Bot %s in %s namespace.", bot.Name, bot.Namespace)
+	c.enqueue(bot)
 }
 
 func (c *Controller) onUpdate(oldObj, newObj interface{}) {
-	new := newObj.(*linev1alpha1.Bot).DeepCopy()
+	new := newObj.(*linev1alpha1.Bot)
 	klog.V(2).Infof("Received onUpdate on Bot %s in %s namespace.", new.Name, new.Namespace)
+	c.enqueue(new)
 }
 
 func (c *Controller) onDelete(obj interface{}) {
 	bot := obj.(*linev1alpha1.Bot).DeepCopy()
 	klog.V(2).Infof("Received onDelete on Bot %s in %s namespace.", bot.Name, bot.Namespace)
+	forgetQuotaMetrics(bot)
 }
 
 func (c *Controller) createBot(bot *linev1alpha1.Bot) error {
-	if err := c.createConfigMap(bot); err != nil {
-		return err
-	}
-	klog.Infof("Success to create configmap on %s in %s namespace.", bot.Name, bot.Namespace)
-
-	if err := c.createService(bot); err != nil {
-		return err
-	}
-	klog.Infof("Success to create service on %s in %s namespace.", bot.Name, bot.Namespace)
-
-	if err := c.createDeployment(bot); err != nil {
+	if err := c.syncChannelToken(bot); err != nil {
 		return err
 	}
-	klog.Infof("Success to create deployment on %s in %s namespace.", bot.Name, bot.Namespace)
 
-	if err := c.createEventBinding(bot); err != nil {
+	if err := c.reconcileOwned(bot); err != nil {
 		return err
 	}
-	klog.Infof("Success to create eventbinding on %s in %s namespace.", bot.Name, bot.Namespace)
 
 	bot.Status.Phase = linev1alpha1.BotActive
 	bot.Status.Reason = ""
@@ -0,0 +1,49 @@
+package bot
+
+import (
+	linev1alpha1 "github.com/kairen/line-bot-operator/pkg/apis/line/v1alpha1"
+	"github.com/kairen/line-bot-operator/pkg/constants"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog"
+)
+
+// migrateNgrokConfigMap moves the ngrok config of a bot created by an older operator
+// from the ConfigMap, which exposes the authtoken, into a Secret.
+func (c *Controller) migrateNgrokConfigMap(bot *linev1alpha1.Bot) error {
+	name := ngrokConfigName(bot)
+	if _, err := c.ctx.Clientset.CoreV1().ConfigMaps(bot.Namespace).Get(name, metav1.GetOptions{}); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	if _, err := c.ensureNgrokSecret(bot); err != nil {
+		return err
+	}
+
+	d, err := c.ctx.Clientset.AppsV1().Deployments(bot.Namespace).Get(bot.Name, metav1.GetOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+
+	if err == nil {
+		for i, volume := range d.Spec.Template.Spec.Volumes {
+			if volume.Name == ngrokConfigVolumeName && volume.ConfigMap != nil {
+				d.Spec.Template.Spec.Volumes[i] = c.makeNgrokConfigVolume(bot)
+			}
+		}
+		if _, err := c.ctx.Clientset.AppsV1().Deployments(bot.Namespace).Update(d); err != nil {
+			return err
+		}
+	}
+
+	if err := c.ctx.Clientset.CoreV1().ConfigMaps(bot.Namespace).Delete(name, &metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	c.recorder.Eventf(bot, v1.EventTypeNormal, constants.ReasonMigrated, "Moved ngrok config from configmap to secret %s", name)
+	klog.Infof("Success to migrate ngrok configmap to secret on %s in %s namespace.", bot.Name, bot.Namespace)
+	return nil
+}
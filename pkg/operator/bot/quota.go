@@ -0,0 +1,243 @@
+package bot
+
+import (
+	"fmt"
+	"time"
+
+	linev1alpha1 "github.com/kairen/line-bot-operator/pkg/apis/line/v1alpha1"
+	"github.com/kairen/line-bot-operator/pkg/constants"
+	"github.com/kairen/line-bot-operator/pkg/lineapi"
+	"github.com/prometheus/client_golang/prometheus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog"
+)
+
+var (
+	quotaLimit = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "line_bot_quota_limit",
+		Help: "Message quota of the current month, 0 without limit.",
+	}, []string{"namespace", "bot"})
+	quotaUsage = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "line_bot_quota_usage",
+		Help: "Messages sent in the current month.",
+	}, []string{"namespace", "bot"})
+	followers = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "line_bot_followers",
+		Help: "Friends of the bot on the last day with insights.",
+	}, []string{"namespace", "bot"})
+	blocks = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "line_bot_blocks",
+		Help: "Users blocking the bot on the last day with insights.",
+	}, []string{"namespace", "bot"})
+	deliveries = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "line_bot_deliveries",
+		Help: "Messages sent on the last day with insights, by kind.",
+	}, []string{"namespace", "bot", "kind"})
+)
+
+var deliveryKinds = []string{"reply", "push", "multicast", "broadcast"}
+
+func init() {
+	prometheus.MustRegister(quotaLimit, quotaUsage, followers, blocks, deliveries)
+}
+
+// apiClient returns a client of the configured Messaging API.
+func (c *Controller) apiClient() *lineapi.Client {
+	client := lineapi.NewClient()
+	client.BaseURL = c.config.Get().APIBaseURL
+	return client
+}
+
+// syncQuota polls the message quota of an active bot, and its insights when
+// enabled, once per poll interval of the operator config.
+func (c *Controller) syncQuota(bot *linev1alpha1.Bot) error {
+	config := c.config.Get().Quota
+	if config.PollInterval.Duration <= 0 || bot.Status.Phase != linev1alpha1.BotActive {
+		return nil
+	}
+
+	now := time.Now()
+	if quota := bot.Status.Quota; quota != nil && now.Sub(quota.PollTime.Time) < config.PollInterval.Duration {
+		return nil
+	}
+
+	token, err := c.getChannelToken(bot)
+	if err != nil {
+		return err
+	}
+
+	client := c.apiClient()
+	status := bot.Status.DeepCopy()
+	quota, pollErr := pollQuota(client, token, now)
+	exhausted := false
+	if pollErr != nil {
+		// The failed poll is recorded, so that it's retried after the poll interval
+		// instead of the backoff of the queue.
+		klog.Errorf("Failed to poll quota on %s in %s namespace: %+v.", bot.Name, bot.Namespace, pollErr)
+		c.recorder.Eventf(bot, v1.EventTypeWarning, constants.ReasonFailedPollQuota, "Failed to poll quota: %v", pollErr)
+		if status.Quota == nil {
+			status.Quota = &linev1alpha1.BotQuota{}
+		}
+		status.Quota.PollTime = metav1.NewTime(now)
+	} else {
+		status.Quota = quota
+
+		// The insights are optional, a failure doesn't hold back the quota.
+		if config.Insights {
+			if insights, err := pollInsights(client, token, now); err != nil {
+				klog.Errorf("Failed to poll insights on %s in %s namespace: %+v.", bot.Name, bot.Namespace, err)
+			} else if insights != nil {
+				status.Insights = insights
+			}
+		}
+
+		exhausted = setQuotaCondition(status, config.Threshold, now)
+		recordQuotaMetrics(bot, status)
+	}
+
+	bot.Status = *status
+	updated, err := c.clientset.LineV1alpha1().Bots(bot.Namespace).Update(bot)
+	if err != nil {
+		return err
+	}
+	updated.DeepCopyInto(bot)
+
+	if exhausted {
+		c.recorder.Eventf(bot, v1.EventTypeWarning, constants.ReasonQuotaNearlyExhausted,
+			"Used %d of %d messages of the monthly quota", status.Quota.TotalUsage, status.Quota.Limit)
+	}
+	if pollErr == nil {
+		klog.V(2).Infof("Success to poll quota on %s in %s namespace.", bot.Name, bot.Namespace)
+	}
+	return nil
+}
+
+func pollQuota(client *lineapi.Client, token string, now time.Time) (*linev1alpha1.BotQuota, error) {
+	quota, err := client.GetQuota(token)
+	if err != nil {
+		return nil, err
+	}
+	usage, err := client.GetConsumption(token)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &linev1alpha1.BotQuota{Type: quota.Type, TotalUsage: usage, PollTime: metav1.NewTime(now)}
+	if quota.Type == lineapi.QuotaLimited {
+		status.Limit = quota.Value
+	}
+	return status, nil
+}
+
+// pollInsights returns the insights of the day before, or nil while they are
+// not ready.
+func pollInsights(client *lineapi.Client, token string, now time.Time) (*linev1alpha1.BotInsights, error) {
+	date := lineapi.InsightDate(now)
+	f, err := client.GetFollowers(token, date)
+	if err != nil {
+		return nil, err
+	}
+	d, err := client.GetDelivery(token, date)
+	if err != nil {
+		return nil, err
+	}
+	if f.Status != lineapi.InsightReady || d.Status != lineapi.InsightReady {
+		return nil, nil
+	}
+
+	return &linev1alpha1.BotInsights{
+		Date:            date,
+		Followers:       f.Followers,
+		TargetedReaches: f.TargetedReaches,
+		Blocks:          f.Blocks,
+		APIReply:        d.APIReply,
+		APIPush:         d.APIPush,
+		APIMulticast:    d.APIMulticast,
+		APIBroadcast:    d.APIBroadcast,
+	}, nil
+}
+
+// setQuotaCondition sets the QuotaNearlyExhausted condition, and reports whether
+// it became true.
+func setQuotaCondition(status *linev1alpha1.BotStatus, threshold int32, now time.Time) bool {
+	quota := status.Quota
+	condition := linev1alpha1.BotCondition{
+		Type:    linev1alpha1.BotQuotaNearlyExhausted,
+		Status:  v1.ConditionFalse,
+		Reason:  "QuotaAvailable",
+		Message: fmt.Sprintf("Used %d messages", quota.TotalUsage),
+	}
+	if quota.Limit > 0 {
+		percent := quota.TotalUsage * 100 / quota.Limit
+		condition.Message = fmt.Sprintf("Used %d of %d messages, %d%%", quota.TotalUsage, quota.Limit, percent)
+		if percent >= int64(threshold) {
+			condition.Status = v1.ConditionTrue
+			condition.Reason = constants.ReasonQuotaNearlyExhausted
+		}
+	}
+
+	for i := range status.Conditions {
+		current := &status.Conditions[i]
+		if current.Type != condition.Type {
+			continue
+		}
+
+		changed := current.Status != condition.Status
+		condition.LastTransitionTime = current.LastTransitionTime
+		if changed {
+			condition.LastTransitionTime = metav1.NewTime(now)
+		}
+		*current = condition
+		return changed && condition.Status == v1.ConditionTrue
+	}
+
+	condition.LastTransitionTime = metav1.NewTime(now)
+	status.Conditions = append(status.Conditions, condition)
+	return condition.Status == v1.ConditionTrue
+}
+
+func recordQuotaMetrics(bot *linev1alpha1.Bot, status *linev1alpha1.BotStatus) {
+	quotaLimit.WithLabelValues(bot.Namespace, bot.Name).Set(float64(status.Quota.Limit))
+	quotaUsage.WithLabelValues(bot.Namespace, bot.Name).Set(float64(status.Quota.TotalUsage))
+
+	insights := status.Insights
+	if insights == nil {
+		return
+	}
+	followers.WithLabelValues(bot.Namespace, bot.Name).Set(float64(insights.Followers))
+	blocks.WithLabelValues(bot.Namespace, bot.Name).Set(float64(insights.Blocks))
+	for i, count := range []int64{insights.APIReply, insights.APIPush, insights.APIMulticast, insights.APIBroadcast} {
+		deliveries.WithLabelValues(bot.Namespace, bot.Name, deliveryKinds[i]).Set(float64(count))
+	}
+}
+
+// forgetQuotaMetrics removes the metrics of a deleted bot.
+func forgetQuotaMetrics(bot *linev1alpha1.Bot) {
+	quotaLimit.DeleteLabelValues(bot.Namespace, bot.Name)
+	quotaUsage.DeleteLabelValues(bot.Namespace, bot.Name)
+	followers.DeleteLabelValues(bot.Namespace, bot.Name)
+	blocks.DeleteLabelValues(bot.Namespace, bot.Name)
+	for _, kind := range deliveryKinds {
+		deliveries.DeleteLabelValues(bot.Namespace, bot.Name, kind)
+	}
+}
+
+// getChannelToken returns the channel access token the bot replies with.
+func (c *Controller) getChannelToken(bot *linev1alpha1.Bot) (string, error) {
+	name := bot.Spec.ChannelSecretName
+	if usesManagedToken(bot) {
+		name = channelTokenName(bot)
+	}
+
+	secret, err := c.ctx.Clientset.CoreV1().Secrets(bot.Namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+
+	token := string(secret.Data[constants.ChannelTokenKey])
+	if token == "" {
+		return "", fmt.Errorf("secret %s has no %q key", name, constants.ChannelTokenKey)
+	}
+	return token, nil
+}
@@ -0,0 +1,119 @@
+package bot
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	linev1alpha1 "github.com/kairen/line-bot-operator/pkg/apis/line/v1alpha1"
+	"github.com/kairen/line-bot-operator/pkg/config"
+	"github.com/kairen/line-bot-operator/pkg/constants"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+// newConfigStore returns a config calling the Messaging API on apiBaseURL.
+func newConfigStore(t *testing.T, apiBaseURL string) *config.Store {
+	f, err := ioutil.TempFile("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	if _, err := fmt.Fprintf(f, "apiBaseURL: %s\n", apiBaseURL); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := config.NewStore(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+// newQuotaController returns a controller polling the Messaging API of server.
+func newQuotaController(t *testing.T, server *httptest.Server) (*Controller, *linev1alpha1.Bot) {
+	c := newTestController(t, &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "line-secret", Namespace: "default"},
+		Data:       map[string][]byte{constants.ChannelTokenKey: []byte("token")},
+	})
+	c.config = newConfigStore(t, server.URL)
+
+	bot := &linev1alpha1.Bot{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-bot", Namespace: "default"},
+		Spec:       linev1alpha1.BotSpec{ChannelSecretName: "line-secret"},
+		Status:     linev1alpha1.BotStatus{Phase: linev1alpha1.BotActive},
+	}
+	bot, err := c.clientset.LineV1alpha1().Bots(bot.Namespace).Create(bot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, bot
+}
+
+func TestSyncQuotaFailedPoll(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		http.Error(w, `{"message":"internal error"}`, http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	c, bot := newQuotaController(t, server)
+
+	if err := c.syncQuota(bot); err != nil {
+		t.Fatalf("got %v, want the failed poll recorded", err)
+	}
+	if bot.Status.Quota == nil || bot.Status.Quota.PollTime.IsZero() {
+		t.Fatalf("got quota %+v, want the poll time", bot.Status.Quota)
+	}
+
+	select {
+	case event := <-c.recorder.(*record.FakeRecorder).Events:
+		if !strings.Contains(event, constants.ReasonFailedPollQuota) {
+			t.Errorf("got event %q, want %s", event, constants.ReasonFailedPollQuota)
+		}
+	default:
+		t.Error("got no warning event")
+	}
+
+	// The next sync waits for the poll interval.
+	if err := c.syncQuota(bot); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 1 {
+		t.Errorf("got %d requests, want 1", requests)
+	}
+}
+
+func TestSyncQuota(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/bot/message/quota":
+			fmt.Fprint(w, `{"type":"limited","value":1000}`)
+		case "/v2/bot/message/quota/consumption":
+			fmt.Fprint(w, `{"totalUsage":950}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	c, bot := newQuotaController(t, server)
+	if err := c.syncQuota(bot); err != nil {
+		t.Fatal(err)
+	}
+
+	quota := bot.Status.Quota
+	if quota == nil || quota.Limit != 1000 || quota.TotalUsage != 950 {
+		t.Fatalf("got quota %+v, want 950 of 1000", quota)
+	}
+	if len(bot.Status.Conditions) != 1 || bot.Status.Conditions[0].Status != v1.ConditionTrue {
+		t.Errorf("got conditions %+v, want the quota nearly exhausted", bot.Status.Conditions)
+	}
+}
@@ -0,0 +1,395 @@
+package bot
+
+import (
+	"reflect"
+
+	linev1alpha1 "github.com/kairen/line-bot-operator/pkg/apis/line/v1alpha1"
+	"github.com/kairen/line-bot-operator/pkg/constants"
+	"github.com/kairen/line-bot-operator/pkg/k8sutil"
+	apps "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	v1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+)
+
+// watchOwned enqueues the bot owning a changed or deleted object, so that its next
+// sync restores the desired state.
+func (c *Controller) watchOwned(namespace string, stopCh chan struct{}) {
+	handler := cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			// Skip the status updates of the objects tracking their generation.
+			old, new := oldObj.(metav1.Object), newObj.(metav1.Object)
+			if new.GetGeneration() != 0 && old.GetGeneration() == new.GetGeneration() &&
+				reflect.DeepEqual(old.GetLabels(), new.GetLabels()) {
+				return
+			}
+			c.enqueueOwner(newObj)
+		},
+		DeleteFunc: c.enqueueOwner,
+	}
+
+	clientset := c.ctx.Clientset
+	sources := []struct {
+		client   cache.Getter
+		resource string
+		obj      runtime.Object
+	}{
+		{clientset.AppsV1().RESTClient(), "deployments", &apps.Deployment{}},
+		{clientset.CoreV1().RESTClient(), "services", &v1.Service{}},
+		{clientset.PolicyV1beta1().RESTClient(), "poddisruptionbudgets", &policyv1beta1.PodDisruptionBudget{}},
+		{clientset.AutoscalingV1().RESTClient(), "horizontalpodautoscalers", &autoscalingv1.HorizontalPodAutoscaler{}},
+		{clientset.CoreV1().RESTClient(), "secrets", &v1.Secret{}},
+	}
+
+	for _, source := range sources {
+		lw := cache.NewFilteredListWatchFromClient(source.client, source.resource, namespace, withBotLabel)
+		_, controller := cache.NewInformer(lw, source.obj, 0, handler)
+		go controller.Run(stopCh)
+	}
+
+	// The subsets of an eventbinding change all the time, only its metadata is
+	// applied by the bot.
+	lw := cache.NewFilteredListWatchFromClient(c.clientset.LineV1alpha1().RESTClient(), "eventbindings", namespace, withBotLabel)
+	_, controller := cache.NewInformer(lw, &linev1alpha1.EventBinding{}, 0, cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			old, new := oldObj.(metav1.Object), newObj.(metav1.Object)
+			if !reflect.DeepEqual(old.GetLabels(), new.GetLabels()) ||
+				!reflect.DeepEqual(old.GetAnnotations(), new.GetAnnotations()) {
+				c.enqueueOwner(newObj)
+			}
+		},
+		DeleteFunc: c.enqueueOwner,
+	})
+	go controller.Run(stopCh)
+}
+
+// withBotLabel only lists the objects labeled with their bot, so that the other
+// objects of the cluster, e.g. all the secrets, are not cached.
+func withBotLabel(options *metav1.ListOptions) {
+	options.LabelSelector = "bot"
+}
+
+// enqueueOwner finds the bot by the controller reference, or by the bot label when
+// the owner references are not supported by the cluster.
+func (c *Controller) enqueueOwner(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+
+	object, ok := obj.(metav1.Object)
+	if !ok {
+		return
+	}
+
+	name := object.GetLabels()["bot"]
+	if ref := metav1.GetControllerOf(object); ref != nil {
+		gv, err := schema.ParseGroupVersion(ref.APIVersion)
+		if err != nil || gv.Group != linev1alpha1.CustomResourceGroup || ref.Kind != Resource.Kind {
+			return
+		}
+		name = ref.Name
+	}
+
+	if name != "" {
+		c.queue.Add(object.GetNamespace() + "/" + name)
+	}
+}
+
+type applyResult int
+
+const (
+	applyUnchanged applyResult = iota
+	applyCreated
+	applyPatched
+	applyDeleted
+)
+
+// reconcileOwned applies the objects of a bot in order, which creates the missing
+// ones and reverts the changes made to the others. It converges after a partial
+// failure, so it both creates and heals a bot. The objects the bot no longer needs,
+// e.g. after its expose type changed, are deleted once the deployment is applied.
+func (c *Controller) reconcileOwned(bot *linev1alpha1.Bot) error {
+	type owned struct {
+		kind  string
+		name  string
+		apply func(*linev1alpha1.Bot) (applyResult, error)
+	}
+
+	isNgrok := isNgrokExpose(bot)
+	objects := []owned{}
+	if isNgrok {
+		objects = append(objects, owned{"secret", ngrokConfigName(bot), c.ensureNgrokSecret})
+	}
+	objects = append(objects,
+		owned{"service", bot.Name, c.ensureService},
+		owned{"deployment", bot.Name, c.ensureDeployment},
+	)
+
+	if isNgrok {
+		objects = append(objects, owned{"poddisruptionbudget", bot.Name, c.removePodDisruptionBudget})
+	} else {
+		objects = append(objects,
+			owned{"secret", ngrokConfigName(bot), c.removeNgrokSecret},
+			owned{"poddisruptionbudget", bot.Name, c.ensurePodDisruptionBudget},
+		)
+	}
+	if !isNgrok && bot.Spec.Autoscaling != nil {
+		objects = append(objects, owned{"horizontalpodautoscaler", bot.Name, c.ensureHorizontalPodAutoscaler})
+	} else {
+		objects = append(objects, owned{"horizontalpodautoscaler", bot.Name, c.removeHorizontalPodAutoscaler})
+	}
+	objects = append(objects, owned{"eventbinding", bot.Name, c.ensureEventBinding})
+
+	for _, object := range objects {
+		result, err := object.apply(bot)
+		if err != nil {
+			c.recorder.Eventf(bot, v1.EventTypeWarning, constants.ReasonFailedCreate, "Failed to apply %s: %v", object.kind, err)
+			return err
+		}
+
+		switch result {
+		case applyCreated:
+			c.recorder.Eventf(bot, v1.EventTypeNormal, constants.ReasonCreated, "Created %s %s", object.kind, object.name)
+			klog.Infof("Success to create %s %s on %s in %s namespace.", object.kind, object.name, bot.Name, bot.Namespace)
+		case applyPatched:
+			c.recorder.Eventf(bot, v1.EventTypeNormal, constants.ReasonUpdated, "Updated %s %s", object.kind, object.name)
+			klog.Infof("Success to update %s %s on %s in %s namespace.", object.kind, object.name, bot.Name, bot.Namespace)
+		case applyDeleted:
+			c.recorder.Eventf(bot, v1.EventTypeNormal, constants.ReasonDeleted, "Deleted %s %s", object.kind, object.name)
+			klog.Infof("Success to delete %s %s on %s in %s namespace.", object.kind, object.name, bot.Name, bot.Namespace)
+		}
+	}
+	return nil
+}
+
+// apply creates the desired object, or patches the fields that differ from the last
+// applied object, the same way as kubectl apply.
+func apply(desired runtime.Object, patchType types.PatchType,
+	get func() (runtime.Object, error), create func() error, patch func([]byte) error) (applyResult, error) {
+	if err := k8sutil.SetLastApplied(desired); err != nil {
+		return applyUnchanged, err
+	}
+
+	current, err := get()
+	if errors.IsNotFound(err) {
+		if err := create(); err != nil {
+			return applyUnchanged, err
+		}
+		return applyCreated, nil
+	} else if err != nil {
+		return applyUnchanged, err
+	}
+
+	p, err := k8sutil.CreateApplyPatch(current, desired, patchType)
+	if err != nil || p == nil {
+		return applyUnchanged, err
+	}
+
+	if err := patch(p); err != nil {
+		return applyUnchanged, err
+	}
+	return applyPatched, nil
+}
+
+// remove deletes an object the bot no longer needs, an object of the same name
+// the bot doesn't own is left alone.
+func remove(bot *linev1alpha1.Bot, get func() (metav1.Object, error), del func() error) (applyResult, error) {
+	current, err := get()
+	if errors.IsNotFound(err) {
+		return applyUnchanged, nil
+	} else if err != nil {
+		return applyUnchanged, err
+	}
+
+	if !isOwnedBy(current, bot) {
+		return applyUnchanged, nil
+	}
+	if err := del(); err != nil {
+		if errors.IsNotFound(err) {
+			return applyUnchanged, nil
+		}
+		return applyUnchanged, err
+	}
+	return applyDeleted, nil
+}
+
+// isOwnedBy checks the controller reference, or the bot label when the owner
+// references are not supported by the cluster.
+func isOwnedBy(object metav1.Object, bot *linev1alpha1.Bot) bool {
+	if ref := metav1.GetControllerOf(object); ref != nil {
+		return ref.UID == bot.UID
+	}
+	return object.GetLabels()["bot"] == bot.Name
+}
+
+// ensureNgrokSecret is not applied, because the last applied annotation would hold
+// the ngrok token.
+func (c *Controller) ensureNgrokSecret(bot *linev1alpha1.Bot) (applyResult, error) {
+	desired, err := c.makeNgrokSecret(bot)
+	if err != nil {
+		return applyUnchanged, err
+	}
+
+	secrets := c.ctx.Clientset.CoreV1().Secrets(bot.Namespace)
+	current, err := secrets.Get(desired.Name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		if _, err := secrets.Create(desired); err != nil {
+			return applyUnchanged, err
+		}
+		return applyCreated, nil
+	} else if err != nil {
+		return applyUnchanged, err
+	}
+
+	if reflect.DeepEqual(desired.Data, current.Data) {
+		return applyUnchanged, nil
+	}
+
+	current.Data = desired.Data
+	if _, err := secrets.Update(current); err != nil {
+		return applyUnchanged, err
+	}
+	return applyPatched, nil
+}
+
+func (c *Controller) removeNgrokSecret(bot *linev1alpha1.Bot) (applyResult, error) {
+	name := ngrokConfigName(bot)
+	secrets := c.ctx.Clientset.CoreV1().Secrets(bot.Namespace)
+	return remove(bot,
+		func() (metav1.Object, error) {
+			return secrets.Get(name, metav1.GetOptions{})
+		},
+		func() error {
+			return secrets.Delete(name, &metav1.DeleteOptions{})
+		})
+}
+
+func (c *Controller) ensureService(bot *linev1alpha1.Bot) (applyResult, error) {
+	desired := c.makeService(bot)
+	services := c.ctx.Clientset.CoreV1().Services(bot.Namespace)
+	return apply(desired, types.StrategicMergePatchType,
+		func() (runtime.Object, error) {
+			return services.Get(desired.Name, metav1.GetOptions{})
+		},
+		func() error {
+			_, err := services.Create(desired)
+			return err
+		},
+		func(patch []byte) error {
+			_, err := services.Patch(desired.Name, types.StrategicMergePatchType, patch)
+			return err
+		})
+}
+
+func (c *Controller) ensureDeployment(bot *linev1alpha1.Bot) (applyResult, error) {
+	desired, err := c.makeDeployment(bot)
+	if err != nil {
+		return applyUnchanged, err
+	}
+
+	deployments := c.ctx.Clientset.AppsV1().Deployments(bot.Namespace)
+	return apply(desired, types.StrategicMergePatchType,
+		func() (runtime.Object, error) {
+			return deployments.Get(desired.Name, metav1.GetOptions{})
+		},
+		func() error {
+			_, err := deployments.Create(desired)
+			return err
+		},
+		func(patch []byte) error {
+			_, err := deployments.Patch(desired.Name, types.StrategicMergePatchType, patch)
+			return err
+		})
+}
+
+func (c *Controller) ensurePodDisruptionBudget(bot *linev1alpha1.Bot) (applyResult, error) {
+	desired := c.makePodDisruptionBudget(bot)
+	pdbs := c.ctx.Clientset.PolicyV1beta1().PodDisruptionBudgets(bot.Namespace)
+	return apply(desired, types.StrategicMergePatchType,
+		func() (runtime.Object, error) {
+			return pdbs.Get(desired.Name, metav1.GetOptions{})
+		},
+		func() error {
+			_, err := pdbs.Create(desired)
+			return err
+		},
+		func(patch []byte) error {
+			_, err := pdbs.Patch(desired.Name, types.StrategicMergePatchType, patch)
+			if !errors.IsInvalid(err) {
+				return err
+			}
+
+			// The spec of a PodDisruptionBudget is immutable, so it's replaced.
+			if err := pdbs.Delete(desired.Name, &metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+				return err
+			}
+			desired.ResourceVersion = ""
+			_, err = pdbs.Create(desired)
+			return err
+		})
+}
+
+func (c *Controller) removePodDisruptionBudget(bot *linev1alpha1.Bot) (applyResult, error) {
+	pdbs := c.ctx.Clientset.PolicyV1beta1().PodDisruptionBudgets(bot.Namespace)
+	return remove(bot,
+		func() (metav1.Object, error) {
+			return pdbs.Get(bot.Name, metav1.GetOptions{})
+		},
+		func() error {
+			return pdbs.Delete(bot.Name, &metav1.DeleteOptions{})
+		})
+}
+
+func (c *Controller) ensureHorizontalPodAutoscaler(bot *linev1alpha1.Bot) (applyResult, error) {
+	desired := c.makeHorizontalPodAutoscaler(bot)
+	hpas := c.ctx.Clientset.AutoscalingV1().HorizontalPodAutoscalers(bot.Namespace)
+	return apply(desired, types.StrategicMergePatchType,
+		func() (runtime.Object, error) {
+			return hpas.Get(desired.Name, metav1.GetOptions{})
+		},
+		func() error {
+			_, err := hpas.Create(desired)
+			return err
+		},
+		func(patch []byte) error {
+			_, err := hpas.Patch(desired.Name, types.StrategicMergePatchType, patch)
+			return err
+		})
+}
+
+func (c *Controller) removeHorizontalPodAutoscaler(bot *linev1alpha1.Bot) (applyResult, error) {
+	hpas := c.ctx.Clientset.AutoscalingV1().HorizontalPodAutoscalers(bot.Namespace)
+	return remove(bot,
+		func() (metav1.Object, error) {
+			return hpas.Get(bot.Name, metav1.GetOptions{})
+		},
+		func() error {
+			return hpas.Delete(bot.Name, &metav1.DeleteOptions{})
+		})
+}
+
+// ensureEventBinding only applies the metadata of the eventbinding, its subsets are
+// written by the eventbinding controller only.
+func (c *Controller) ensureEventBinding(bot *linev1alpha1.Bot) (applyResult, error) {
+	desired := c.makeEventBinding(bot)
+	eventBindings := c.clientset.LineV1alpha1().EventBindings(bot.Namespace)
+	return apply(desired, types.MergePatchType,
+		func() (runtime.Object, error) {
+			return eventBindings.Get(desired.Name, metav1.GetOptions{})
+		},
+		func() error {
+			_, err := eventBindings.Create(desired)
+			return err
+		},
+		func(patch []byte) error {
+			_, err := eventBindings.Patch(desired.Name, types.MergePatchType, patch)
+			return err
+		})
+}
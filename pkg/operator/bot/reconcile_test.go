@@ -0,0 +1,121 @@
+package bot
+
+import (
+	"testing"
+
+	linev1alpha1 "github.com/kairen/line-bot-operator/pkg/apis/line/v1alpha1"
+	"github.com/kairen/line-bot-operator/pkg/constants"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestReconcileOwnedExposeSwitch(t *testing.T) {
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "line-secret", Namespace: "default"},
+		Data: map[string][]byte{
+			constants.ChannelSecretKey: []byte("secret"),
+			constants.ChannelTokenKey:  []byte("token"),
+			constants.NgrokTokenKey:    []byte("ngrok"),
+		},
+	}
+	c := newTestController(t, secret)
+	kube := c.ctx.Clientset
+
+	bot := &linev1alpha1.Bot{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-bot", Namespace: "default", UID: "test-bot-uid"},
+		Spec: linev1alpha1.BotSpec{
+			Selector:          &metav1.LabelSelector{MatchLabels: map[string]string{"hunter": "monster"}},
+			ChannelSecretName: secret.Name,
+		},
+	}
+
+	exists := func(get func() error) bool {
+		err := get()
+		if err != nil && !errors.IsNotFound(err) {
+			t.Fatal(err)
+		}
+		return err == nil
+	}
+	owned := func() (ngrokSecret, pdb, hpa bool) {
+		ngrokSecret = exists(func() error {
+			_, err := kube.CoreV1().Secrets(bot.Namespace).Get(ngrokConfigName(bot), metav1.GetOptions{})
+			return err
+		})
+		pdb = exists(func() error {
+			_, err := kube.PolicyV1beta1().PodDisruptionBudgets(bot.Namespace).Get(bot.Name, metav1.GetOptions{})
+			return err
+		})
+		hpa = exists(func() error {
+			_, err := kube.AutoscalingV1().HorizontalPodAutoscalers(bot.Namespace).Get(bot.Name, metav1.GetOptions{})
+			return err
+		})
+		return
+	}
+
+	autoscaling := &linev1alpha1.BotAutoscaling{MaxReplicas: 3}
+	tests := []struct {
+		name        string
+		expose      linev1alpha1.BotExposeType
+		autoscaling *linev1alpha1.BotAutoscaling
+		ngrokSecret bool
+		pdb         bool
+		hpa         bool
+	}{
+		{"ngrok", linev1alpha1.NgrokExpose, nil, true, false, false},
+		{"ngrok to ingress", linev1alpha1.IngressExpose, nil, false, true, false},
+		{"autoscaling", linev1alpha1.IngressExpose, autoscaling, false, true, true},
+		{"no autoscaling", linev1alpha1.LoadBalancerExpose, nil, false, true, false},
+		{"autoscaling again", linev1alpha1.LoadBalancerExpose, autoscaling, false, true, true},
+		{"back to ngrok", linev1alpha1.NgrokExpose, autoscaling, true, false, false},
+	}
+
+	for _, test := range tests {
+		bot.Spec.Expose.Type = test.expose
+		bot.Spec.Autoscaling = test.autoscaling
+		if err := c.reconcileOwned(bot); err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+
+		ngrokSecret, pdb, hpa := owned()
+		if ngrokSecret != test.ngrokSecret || pdb != test.pdb || hpa != test.hpa {
+			t.Errorf("%s: got ngrok secret %v, pdb %v and hpa %v, want %v, %v and %v",
+				test.name, ngrokSecret, pdb, hpa, test.ngrokSecret, test.pdb, test.hpa)
+		}
+	}
+}
+
+func TestReconcileOwnedKeepsForeignObjects(t *testing.T) {
+	c := newTestController(t, &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "line-secret", Namespace: "default"},
+		Data: map[string][]byte{
+			constants.ChannelSecretKey: []byte("secret"),
+			constants.ChannelTokenKey:  []byte("token"),
+		},
+	})
+
+	// The HPA has the name of the bot, but isn't created by the operator.
+	hpa := &autoscalingv1.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-bot", Namespace: "default"},
+		Spec:       autoscalingv1.HorizontalPodAutoscalerSpec{MaxReplicas: 2},
+	}
+	if _, err := c.ctx.Clientset.AutoscalingV1().HorizontalPodAutoscalers(hpa.Namespace).Create(hpa); err != nil {
+		t.Fatal(err)
+	}
+
+	bot := &linev1alpha1.Bot{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-bot", Namespace: "default", UID: "test-bot-uid"},
+		Spec: linev1alpha1.BotSpec{
+			Selector:          &metav1.LabelSelector{MatchLabels: map[string]string{"hunter": "monster"}},
+			ChannelSecretName: "line-secret",
+			Expose:            linev1alpha1.BotExpose{Type: linev1alpha1.LoadBalancerExpose},
+		},
+	}
+	if err := c.reconcileOwned(bot); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.ctx.Clientset.AutoscalingV1().HorizontalPodAutoscalers(hpa.Namespace).Get(hpa.Name, metav1.GetOptions{}); err != nil {
+		t.Errorf("got %v, want the foreign hpa kept", err)
+	}
+}
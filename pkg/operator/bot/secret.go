@@ -0,0 +1,227 @@
+package bot
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	linev1alpha1 "github.com/kairen/line-bot-operator/pkg/apis/line/v1alpha1"
+	"github.com/kairen/line-bot-operator/pkg/constants"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+)
+
+// channelSecretIndex indexes the cached bots by the namespaced name of their
+// channel secret.
+const channelSecretIndex = "channelSecret"
+
+func indexChannelSecret(obj interface{}) ([]string, error) {
+	bot, ok := obj.(*linev1alpha1.Bot)
+	if !ok || bot.Spec.ChannelSecretName == "" {
+		return nil, nil
+	}
+	return []string{bot.Namespace + "/" + bot.Spec.ChannelSecretName}, nil
+}
+
+// watchBots caches the bots, so that a secret change finds its bots without
+// listing them from the API server, and watches the channel secrets they reference.
+func (c *Controller) watchBots(namespace string, stopCh chan struct{}) {
+	source := cache.NewListWatchFromClient(c.clientset.LineV1alpha1().RESTClient(), customResourceNamePlural, namespace, fields.Everything())
+	indexer, controller := cache.NewIndexerInformer(source, &linev1alpha1.Bot{}, 0, cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { c.syncSecretWatches(stopCh) },
+		UpdateFunc: func(oldObj, newObj interface{}) { c.syncSecretWatches(stopCh) },
+		DeleteFunc: func(obj interface{}) { c.syncSecretWatches(stopCh) },
+	}, cache.Indexers{channelSecretIndex: indexChannelSecret})
+	c.bots = indexer
+	go controller.Run(stopCh)
+}
+
+// syncSecretWatches starts a watch for each channel secret referenced by a cached
+// bot, and stops the watches of the secrets no bot references anymore.
+func (c *Controller) syncSecretWatches(stopCh chan struct{}) {
+	c.secretsMu.Lock()
+	defer c.secretsMu.Unlock()
+
+	if c.secretWatches == nil {
+		c.secretWatches = map[string]chan struct{}{}
+	}
+
+	referenced := map[string]bool{}
+	for _, obj := range c.bots.List() {
+		keys, _ := indexChannelSecret(obj)
+		for _, key := range keys {
+			referenced[key] = true
+			if _, ok := c.secretWatches[key]; !ok {
+				c.secretWatches[key] = c.watchSecret(key, stopCh)
+			}
+		}
+	}
+
+	for key, stop := range c.secretWatches {
+		if !referenced[key] {
+			close(stop)
+			delete(c.secretWatches, key)
+		}
+	}
+}
+
+// watchSecret rolls the bots of a channel secret when it changes, because the
+// channel secret and token are injected as environment variables. The watch is
+// restricted to the secret by a field selector, so that the other secrets of the
+// cluster are not cached. The returned channel stops the watch.
+func (c *Controller) watchSecret(key string, stopCh chan struct{}) chan struct{} {
+	namespace, name, _ := cache.SplitMetaNamespaceKey(key)
+	secrets := c.ctx.Clientset.CoreV1().Secrets(namespace)
+	selector := fields.OneTermEqualSelector("metadata.name", name).String()
+	source := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			options.FieldSelector = selector
+			return secrets.List(options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			options.FieldSelector = selector
+			return secrets.Watch(options)
+		},
+	}
+	_, controller := cache.NewInformer(source, &v1.Secret{}, 0, cache.ResourceEventHandlerFuncs{
+		AddFunc: c.onSecretChange,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			if !reflect.DeepEqual(oldObj.(*v1.Secret).Data, newObj.(*v1.Secret).Data) {
+				c.onSecretChange(newObj)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			c.onSecretChange(obj)
+		},
+	})
+
+	stop, done := make(chan struct{}), make(chan struct{})
+	go func() {
+		select {
+		case <-stopCh:
+		case <-stop:
+		}
+		close(done)
+	}()
+	go controller.Run(done)
+	return stop
+}
+
+func (c *Controller) onSecretChange(obj interface{}) {
+	secret, ok := obj.(*v1.Secret)
+	if !ok {
+		return
+	}
+
+	bots, err := c.bots.ByIndex(channelSecretIndex, secret.Namespace+"/"+secret.Name)
+	if err != nil {
+		klog.Errorf("Failed to find bots for secret %s in %s namespace: %+v.", secret.Name, secret.Namespace, err)
+		return
+	}
+
+	for _, obj := range bots {
+		c.enqueue(obj.(*linev1alpha1.Bot))
+	}
+}
+
+// syncSecretHash reports the channel secret errors in the bot status, and the
+// changes of the channel secret, which roll the deployment.
+func (c *Controller) syncSecretHash(bot *linev1alpha1.Bot) error {
+	hash, err := c.channelSecretHash(bot)
+	if err != nil {
+		if statusErr := c.setSecretReason(bot, fmt.Sprintf("Failed to resolve channel secret %s: %v", bot.Spec.ChannelSecretName, err)); statusErr != nil {
+			return statusErr
+		}
+		return err
+	}
+
+	if err := c.setSecretReason(bot, ""); err != nil {
+		return err
+	}
+
+	d, err := c.ctx.Clientset.AppsV1().Deployments(bot.Namespace).Get(bot.Name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	// The deployment is rolled by applying its pod template, which holds the hash.
+	if d.Spec.Template.Annotations[constants.SecretHashAnnotation] != hash {
+		c.recorder.Eventf(bot, v1.EventTypeNormal, constants.ReasonSecretChanged,
+			"Rolling deployment %s for the changed secret %s", bot.Name, bot.Spec.ChannelSecretName)
+	}
+	return nil
+}
+
+func (c *Controller) channelSecretHash(bot *linev1alpha1.Bot) (string, error) {
+	secret, err := c.ctx.Clientset.CoreV1().Secrets(bot.Namespace).Get(bot.Spec.ChannelSecretName, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+
+	keys := []string{}
+	for key := range secret.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	for _, key := range keys {
+		fmt.Fprintf(h, "%s=%x\n", key, secret.Data[key])
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// setSecretReason keeps the status reason of an active bot in line with the
+// resolution of its channel secret.
+func (c *Controller) setSecretReason(bot *linev1alpha1.Bot, reason string) error {
+	if bot.Status.Reason == reason {
+		return nil
+	}
+
+	bot.Status.Reason = reason
+	bot.Status.LastUpdateTime = metav1.NewTime(time.Now())
+	updated, err := c.clientset.LineV1alpha1().Bots(bot.Namespace).Update(bot)
+	if err != nil {
+		return err
+	}
+	updated.DeepCopyInto(bot)
+	return nil
+}
+
+func (c *Controller) patchPodTemplateAnnotation(bot *linev1alpha1.Bot, key, value string) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]string{key: value},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = c.ctx.Clientset.AppsV1().Deployments(bot.Namespace).Patch(bot.Name, types.StrategicMergePatchType, patch)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
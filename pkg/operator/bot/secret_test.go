@@ -0,0 +1,91 @@
+package bot
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	linev1alpha1 "github.com/kairen/line-bot-operator/pkg/apis/line/v1alpha1"
+	"github.com/kairen/line-bot-operator/pkg/constants"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// expectQueued waits for the worker queue to hold the key of a bot.
+func expectQueued(t *testing.T, c *Controller, key string) {
+	got := make(chan interface{})
+	go func() {
+		item, _ := c.queue.Get()
+		c.queue.Done(item)
+		got <- item
+	}()
+
+	select {
+	case item := <-got:
+		if item != key {
+			t.Errorf("got %v queued, want %s", item, key)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("got nothing queued, want %s", key)
+	}
+}
+
+func TestSyncSecretWatches(t *testing.T) {
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "line-secret", Namespace: "default"},
+		Data:       map[string][]byte{constants.ChannelSecretKey: []byte("secret")},
+	}
+	c := newTestController(t, secret)
+	c.queue = workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), customResourceNamePlural)
+	c.bots = cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{channelSecretIndex: indexChannelSecret})
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	var mu sync.Mutex
+	selectors := []string{}
+	c.ctx.Clientset.(*fake.Clientset).PrependReactor("list", "secrets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		selectors = append(selectors, action.(k8stesting.ListAction).GetListRestrictions().Fields.String())
+		return false, nil, nil
+	})
+
+	bot := &linev1alpha1.Bot{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-bot", Namespace: "default"},
+		Spec:       linev1alpha1.BotSpec{ChannelSecretName: "line-secret"},
+	}
+	if err := c.bots.Add(bot); err != nil {
+		t.Fatal(err)
+	}
+	c.syncSecretWatches(stopCh)
+	if _, ok := c.secretWatches["default/line-secret"]; !ok || len(c.secretWatches) != 1 {
+		t.Fatalf("got watches %v, want default/line-secret", c.secretWatches)
+	}
+
+	// The initial list finds the secret, then its changes roll the bot.
+	expectQueued(t, c, "default/test-bot")
+	secret.Data[constants.ChannelSecretKey] = []byte("rotated")
+	if _, err := c.ctx.Clientset.CoreV1().Secrets("default").Update(secret); err != nil {
+		t.Fatal(err)
+	}
+	expectQueued(t, c, "default/test-bot")
+
+	mu.Lock()
+	if len(selectors) != 1 || selectors[0] != "metadata.name=line-secret" {
+		t.Errorf("got list field selectors %v, want metadata.name=line-secret", selectors)
+	}
+	mu.Unlock()
+
+	if err := c.bots.Delete(bot); err != nil {
+		t.Fatal(err)
+	}
+	c.syncSecretWatches(stopCh)
+	if len(c.secretWatches) != 0 {
+		t.Errorf("got watches %v, want none", c.secretWatches)
+	}
+}
@@ -2,7 +2,9 @@ package bot
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
+	"path"
 	"reflect"
 	"sort"
 
@@ -11,11 +13,23 @@ import (
 	linev1alpha1 "github.com/kairen/line-bot-operator/pkg/apis/line/v1alpha1"
 	"github.com/kairen/line-bot-operator/pkg/constants"
 	"github.com/kairen/line-bot-operator/pkg/k8sutil"
+	"github.com/kairen/line-bot-operator/pkg/operator/eventbinding"
+	"github.com/kairen/line-bot-operator/pkg/rules"
 	apps "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	v1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/klog"
+)
+
+const (
+	ngrokConfigVolumeName = "ngrok-config"
+	rulesVolumeName       = "rules"
+	rulesMountPath        = "/etc/linebot/rules"
 )
 
 var ngrokConfigTmpl = template.Must(template.New("ngork-configTmpl").Funcs(template.FuncMap{
@@ -25,40 +39,45 @@ update: false
 log: stdout
 authtoken: {{.Authtoken}}`))
 
-func (c *Controller) createConfigMap(bot *linev1alpha1.Bot) error {
+// makeNgrokSecret renders the ngrok config into a Secret, because it holds the
+// authtoken of the channel secret.
+func (c *Controller) makeNgrokSecret(bot *linev1alpha1.Bot) (*v1.Secret, error) {
 	secret, err := c.ctx.Clientset.CoreV1().Secrets(bot.Namespace).Get(bot.Spec.ChannelSecretName, metav1.GetOptions{})
 	if err != nil {
-		return err
+		if errors.IsNotFound(err) {
+			c.recorder.Eventf(bot, v1.EventTypeWarning, constants.ReasonSecretNotFound,
+				"Channel secret %s not found", bot.Spec.ChannelSecretName)
+		}
+		return nil, err
 	}
 
 	opts := struct {
 		Authtoken string
 	}{
-		Authtoken: string(secret.Data["ngrokToken"]),
+		Authtoken: string(secret.Data[constants.NgrokTokenKey]),
 	}
 	b := bytes.Buffer{}
 	if err := ngrokConfigTmpl.Execute(&b, opts); err != nil {
-		return err
+		return nil, err
 	}
 
-	cm := &v1.ConfigMap{
+	ngrokSecret := &v1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      fmt.Sprintf("ngrok-%s-config", bot.Name),
+			Name:      ngrokConfigName(bot),
 			Namespace: bot.Namespace,
+			Labels:    map[string]string{"bot": bot.Name},
 		},
-		Data: map[string]string{
-			"ngrok.yml": b.String(),
+		Type: v1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			"ngrok.yml": b.Bytes(),
 		},
 	}
 
-	k8sutil.SetOwnerRef(c.ctx.Clientset, bot.Namespace, &cm.ObjectMeta, c.makeOnwerRefer(bot))
-	if _, err := c.ctx.Clientset.CoreV1().ConfigMaps(bot.Namespace).Create(cm); err != nil {
-		return err
-	}
-	return nil
+	k8sutil.SetOwnerRef(c.ctx.Clientset, bot.Namespace, &ngrokSecret.ObjectMeta, c.makeOnwerRefer(bot))
+	return ngrokSecret, nil
 }
 
-func (c *Controller) createService(bot *linev1alpha1.Bot) error {
+func (c *Controller) makeService(bot *linev1alpha1.Bot) *v1.Service {
 	svc := &v1.Service{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      bot.Name,
@@ -70,57 +89,72 @@ func (c *Controller) createService(bot *linev1alpha1.Bot) error {
 			Type:     v1.ServiceTypeNodePort,
 			Ports: []v1.ServicePort{
 				{
-					Name:     "bot-http",
-					Port:     int32(8080),
-					Protocol: v1.ProtocolTCP,
-				},
-				{
-					Name:     "ngrok-http",
-					Port:     int32(4040),
-					Protocol: v1.ProtocolTCP,
+					Name:       "bot-http",
+					Port:       int32(8080),
+					TargetPort: intstr.FromInt(8080),
+					Protocol:   v1.ProtocolTCP,
 				},
 			},
 		},
 	}
 
-	k8sutil.SetOwnerRef(c.ctx.Clientset, bot.Namespace, &svc.ObjectMeta, c.makeOnwerRefer(bot))
-	_, err := c.ctx.Clientset.CoreV1().Services(bot.Namespace).Create(svc)
-	if err != nil {
-		return err
+	if isNgrokExpose(bot) {
+		svc.Spec.Ports = append(svc.Spec.Ports, v1.ServicePort{
+			Name:       "ngrok-http",
+			Port:       int32(4040),
+			TargetPort: intstr.FromInt(4040),
+			Protocol:   v1.ProtocolTCP,
+		})
 	}
-	return nil
+
+	k8sutil.SetOwnerRef(c.ctx.Clientset, bot.Namespace, &svc.ObjectMeta, c.makeOnwerRefer(bot))
+	return svc
 }
 
-func (c *Controller) createDeployment(bot *linev1alpha1.Bot) error {
-	defaultMode := new(int32)
-	*defaultMode = 420
+func (c *Controller) makeDeployment(bot *linev1alpha1.Bot) (*apps.Deployment, error) {
+	config := c.config.Get()
 
-	podSpec := v1.PodTemplateSpec{
+	// The hash rolls the pods when the channel secret changes, see syncSecretHash.
+	secretHash, err := c.channelSecretHash(bot)
+	if err != nil {
+		return nil, err
+	}
+
+	podSpec := &v1.PodTemplateSpec{
 		ObjectMeta: metav1.ObjectMeta{
-			Labels: map[string]string{"bot": bot.Name},
+			Labels:      map[string]string{"bot": bot.Name},
+			Annotations: map[string]string{constants.SecretHashAnnotation: secretHash},
 		},
 		Spec: v1.PodSpec{
-			ServiceAccountName: constants.ServiceAccountName,
+			ServiceAccountName: config.ServiceAccountName,
+			ImagePullSecrets:   config.ImagePullSecrets,
 			Containers: []v1.Container{
 				c.makeBotContainer(bot),
-				c.makeNgrokContainer(bot),
 			},
 			RestartPolicy: v1.RestartPolicyAlways,
 			Volumes: []v1.Volume{
-				v1.Volume{
-					Name: "ngrok-config",
-					VolumeSource: v1.VolumeSource{
-						ConfigMap: &v1.ConfigMapVolumeSource{
-							LocalObjectReference: v1.LocalObjectReference{Name: fmt.Sprintf("ngrok-%s-config", bot.Name)},
-							DefaultMode:          defaultMode,
-						},
-					},
-				},
+				makeRulesVolume(bot),
 			},
 		},
 	}
 
-	replicas := int32(1)
+	// Only the ngrok expose runs the tunnel, its single replica owns the authtoken.
+	if isNgrokExpose(bot) {
+		podSpec.Spec.Containers = append(podSpec.Spec.Containers, c.makeNgrokContainer(bot))
+		podSpec.Spec.Volumes = append(podSpec.Spec.Volumes, c.makeNgrokConfigVolume(bot))
+	}
+
+	podSpec, err = k8sutil.MergePodTemplate(podSpec, bot.Spec.PodTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge the pod template: %v", err)
+	}
+
+	// The label is used by the deployment and service selectors, so it can't be overridden.
+	if podSpec.Labels == nil {
+		podSpec.Labels = map[string]string{}
+	}
+	podSpec.Labels["bot"] = bot.Name
+
 	d := &apps.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      bot.Name,
@@ -131,20 +165,105 @@ func (c *Controller) createDeployment(bot *linev1alpha1.Bot) error {
 			Selector: &metav1.LabelSelector{
 				MatchLabels: map[string]string{"bot": bot.Name},
 			},
-			Template: podSpec,
-			Replicas: &replicas,
-			Strategy: apps.DeploymentStrategy{
-				Type: apps.RecreateDeploymentStrategyType,
-			},
+			Template: *podSpec,
+			Replicas: botReplicas(bot),
+			Strategy: makeDeploymentStrategy(bot),
 		},
 	}
 
 	k8sutil.SetOwnerRef(c.ctx.Clientset, bot.Namespace, &d.ObjectMeta, c.makeOnwerRefer(bot))
-	_, err := c.ctx.Clientset.AppsV1().Deployments(bot.Namespace).Create(d)
-	if err != nil {
-		return err
+	return d, nil
+}
+
+// makeDeploymentStrategy recreates the ngrok pod, because a second ngrok client
+// can't open the same tunnel. The other exposes roll without dropping webhooks.
+func makeDeploymentStrategy(bot *linev1alpha1.Bot) apps.DeploymentStrategy {
+	if isNgrokExpose(bot) {
+		return apps.DeploymentStrategy{Type: apps.RecreateDeploymentStrategyType}
+	}
+
+	maxUnavailable := intstr.FromInt(0)
+	maxSurge := intstr.FromInt(1)
+	return apps.DeploymentStrategy{
+		Type: apps.RollingUpdateDeploymentStrategyType,
+		RollingUpdate: &apps.RollingUpdateDeployment{
+			MaxUnavailable: &maxUnavailable,
+			MaxSurge:       &maxSurge,
+		},
+	}
+}
+
+func (c *Controller) makeHorizontalPodAutoscaler(bot *linev1alpha1.Bot) *autoscalingv1.HorizontalPodAutoscaler {
+	hpa := &autoscalingv1.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      bot.Name,
+			Namespace: bot.Namespace,
+			Labels:    map[string]string{"bot": bot.Name},
+		},
+		Spec: autoscalingv1.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv1.CrossVersionObjectReference{
+				APIVersion: apps.SchemeGroupVersion.String(),
+				Kind:       "Deployment",
+				Name:       bot.Name,
+			},
+			MinReplicas:                    bot.Spec.Autoscaling.MinReplicas,
+			MaxReplicas:                    bot.Spec.Autoscaling.MaxReplicas,
+			TargetCPUUtilizationPercentage: bot.Spec.Autoscaling.TargetCPUUtilizationPercentage,
+		},
+	}
+
+	k8sutil.SetOwnerRef(c.ctx.Clientset, bot.Namespace, &hpa.ObjectMeta, c.makeOnwerRefer(bot))
+	return hpa
+}
+
+func (c *Controller) makePodDisruptionBudget(bot *linev1alpha1.Bot) *policyv1beta1.PodDisruptionBudget {
+	maxUnavailable := intstr.FromInt(1)
+	pdb := &policyv1beta1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      bot.Name,
+			Namespace: bot.Namespace,
+			Labels:    map[string]string{"bot": bot.Name},
+		},
+		Spec: policyv1beta1.PodDisruptionBudgetSpec{
+			MaxUnavailable: &maxUnavailable,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"bot": bot.Name},
+			},
+		},
+	}
+
+	k8sutil.SetOwnerRef(c.ctx.Clientset, bot.Namespace, &pdb.ObjectMeta, c.makeOnwerRefer(bot))
+	return pdb
+}
+
+func (c *Controller) makeNgrokConfigVolume(bot *linev1alpha1.Bot) v1.Volume {
+	defaultMode := new(int32)
+	*defaultMode = 420
+
+	return v1.Volume{
+		Name: ngrokConfigVolumeName,
+		VolumeSource: v1.VolumeSource{
+			Secret: &v1.SecretVolumeSource{
+				SecretName:  ngrokConfigName(bot),
+				DefaultMode: defaultMode,
+			},
+		},
+	}
+}
+
+// makeRulesVolume mounts the rule snapshot compiled from the eventbinding, the
+// kubelet swaps the whole directory when the snapshot changes.
+func makeRulesVolume(bot *linev1alpha1.Bot) v1.Volume {
+	optional := true
+	return v1.Volume{
+		Name: rulesVolumeName,
+		VolumeSource: v1.VolumeSource{
+			ConfigMap: &v1.ConfigMapVolumeSource{
+				LocalObjectReference: v1.LocalObjectReference{Name: eventbinding.RulesName(bot.Name)},
+				Optional:             &optional,
+			},
+		},
 	}
-	return nil
 }
 
 func (c *Controller) makeBotContainer(bot *linev1alpha1.Bot) v1.Container {
@@ -153,17 +272,25 @@ func (c *Controller) makeBotContainer(bot *linev1alpha1.Bot) v1.Container {
 		namespace = "default"
 	}
 
+	// A managed token is stored in its own secret, see syncChannelToken.
+	tokenSecretName := bot.Spec.ChannelSecretName
+	if usesManagedToken(bot) {
+		tokenSecretName = channelTokenName(bot)
+	}
+
+	config := c.config.Get()
 	container := v1.Container{
-		Name:  "linebot",
-		Image: fmt.Sprintf("%s:%s", constants.BotImageName, bot.Spec.Version),
-		Args:  []string{"--logtostderr", fmt.Sprintf("--v=%d", bot.Spec.LogLevel)},
+		Name:      "linebot",
+		Image:     fmt.Sprintf("%s:%s", config.BotImage, bot.Spec.Version),
+		Resources: resourcesOrDefault(bot.Spec.Resources.Bot, config.Resources.Bot),
+		Args:      []string{"--logtostderr", fmt.Sprintf("--v=%d", bot.Spec.LogLevel)},
 		Env: []v1.EnvVar{
 			v1.EnvVar{
 				Name: "CHANNEL_SECRET",
 				ValueFrom: &v1.EnvVarSource{
 					SecretKeyRef: &v1.SecretKeySelector{
 						LocalObjectReference: v1.LocalObjectReference{Name: bot.Spec.ChannelSecretName},
-						Key:                  "channelSecret",
+						Key:                  constants.ChannelSecretKey,
 					},
 				},
 			},
@@ -171,8 +298,8 @@ func (c *Controller) makeBotContainer(bot *linev1alpha1.Bot) v1.Container {
 				Name: "CHANNEL_TOKEN",
 				ValueFrom: &v1.EnvVarSource{
 					SecretKeyRef: &v1.SecretKeySelector{
-						LocalObjectReference: v1.LocalObjectReference{Name: bot.Spec.ChannelSecretName},
-						Key:                  "channelToken",
+						LocalObjectReference: v1.LocalObjectReference{Name: tokenSecretName},
+						Key:                  constants.ChannelTokenKey,
 					},
 				},
 			},
@@ -188,6 +315,17 @@ func (c *Controller) makeBotContainer(bot *linev1alpha1.Bot) v1.Container {
 				Name:  "NAMESPACE",
 				Value: namespace,
 			},
+			v1.EnvVar{
+				Name:  "RULES_FILE",
+				Value: path.Join(rulesMountPath, rules.SnapshotKey),
+			},
+		},
+		VolumeMounts: []v1.VolumeMount{
+			v1.VolumeMount{
+				Name:      rulesVolumeName,
+				MountPath: rulesMountPath,
+				ReadOnly:  true,
+			},
 		},
 		Ports: []v1.ContainerPort{
 			{
@@ -211,13 +349,25 @@ func (c *Controller) makeBotContainer(bot *linev1alpha1.Bot) v1.Container {
 			TimeoutSeconds:      3,
 		},
 	}
+
+	// The runtime reads the rate limit from the environment, see ratelimit.Load.
+	if bot.Spec.RateLimit != nil {
+		data, err := json.Marshal(bot.Spec.RateLimit)
+		if err != nil {
+			klog.Errorf("Failed to encode rate limit on %s in %s namespace: %+v.", bot.Name, namespace, err)
+		} else {
+			container.Env = append(container.Env, v1.EnvVar{Name: "RATE_LIMIT", Value: string(data)})
+		}
+	}
 	return container
 }
 
 func (c *Controller) makeNgrokContainer(bot *linev1alpha1.Bot) v1.Container {
+	config := c.config.Get()
 	container := v1.Container{
-		Name:  "ngrok",
-		Image: fmt.Sprintf("%s:%s", constants.NgrokImageName, bot.Spec.Version),
+		Name:      "ngrok",
+		Image:     fmt.Sprintf("%s:%s", config.NgrokImage, bot.Spec.Version),
+		Resources: resourcesOrDefault(bot.Spec.Resources.Ngrok, config.Resources.Ngrok),
 		Command: []string{
 			"./ngrok",
 			"http",
@@ -246,7 +396,7 @@ func (c *Controller) makeNgrokContainer(bot *linev1alpha1.Bot) v1.Container {
 		},
 		VolumeMounts: []v1.VolumeMount{
 			v1.VolumeMount{
-				Name:      "ngrok-config",
+				Name:      ngrokConfigVolumeName,
 				MountPath: "/home/ngrok/.ngrok2",
 			},
 		},
@@ -254,21 +404,52 @@ func (c *Controller) makeNgrokContainer(bot *linev1alpha1.Bot) v1.Container {
 	return container
 }
 
-func (c *Controller) createEventBinding(bot *linev1alpha1.Bot) error {
+func (c *Controller) makeEventBinding(bot *linev1alpha1.Bot) *linev1alpha1.EventBinding {
 	eb := &linev1alpha1.EventBinding{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      bot.Name,
 			Namespace: bot.Namespace,
-			Labels:    bot.Spec.Selector.MatchLabels,
+			Labels:    map[string]string{"bot": bot.Name},
 		},
 	}
 
+	// Copy the labels, applying the eventbinding adds labels of its own. The bot
+	// label lets watchOwned find the eventbinding, unless the selector overrides it.
+	for key, value := range bot.Spec.Selector.MatchLabels {
+		eb.Labels[key] = value
+	}
+
 	k8sutil.SetOwnerRef(c.ctx.Clientset, bot.Namespace, &eb.ObjectMeta, c.makeOnwerRefer(bot))
-	_, err := c.clientset.LineV1alpha1().EventBindings(bot.Namespace).Create(eb)
-	if err != nil {
-		return err
+	return eb
+}
+
+func resourcesOrDefault(resources, defaults v1.ResourceRequirements) v1.ResourceRequirements {
+	if len(resources.Limits) == 0 && len(resources.Requests) == 0 {
+		return defaults
 	}
-	return nil
+	return resources
+}
+
+// botReplicas returns the replicas of the bot deployment, which are left to the
+// autoscaler when it is enabled.
+func botReplicas(bot *linev1alpha1.Bot) *int32 {
+	replicas := int32(1)
+	switch {
+	case isNgrokExpose(bot):
+	case bot.Spec.Autoscaling != nil:
+		return nil
+	case bot.Spec.Replicas != nil:
+		replicas = *bot.Spec.Replicas
+	}
+	return &replicas
+}
+
+func isNgrokExpose(bot *linev1alpha1.Bot) bool {
+	return bot.Spec.Expose.Type == "" || bot.Spec.Expose.Type == linev1alpha1.NgrokExpose
+}
+
+func ngrokConfigName(bot *linev1alpha1.Bot) string {
+	return fmt.Sprintf("ngrok-%s-config", bot.Name)
 }
 
 func (c *Controller) makeOnwerRefer(bot *linev1alpha1.Bot) *metav1.OwnerReference {
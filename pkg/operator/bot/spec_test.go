@@ -0,0 +1,93 @@
+package bot
+
+import (
+	"testing"
+
+	linev1alpha1 "github.com/kairen/line-bot-operator/pkg/apis/line/v1alpha1"
+	"github.com/kairen/line-bot-operator/pkg/config"
+	"github.com/kairen/line-bot-operator/pkg/constants"
+	linefake "github.com/kairen/line-bot-operator/pkg/generated/clientset/versioned/fake"
+	opkit "github.com/kubedev/operator-kit"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+)
+
+func newTestController(t *testing.T, objects ...*v1.Secret) *Controller {
+	store, err := config.NewStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clientset := fake.NewSimpleClientset()
+	for _, secret := range objects {
+		if _, err := clientset.CoreV1().Secrets(secret.Namespace).Create(secret); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return &Controller{
+		ctx:       &opkit.Context{Clientset: clientset},
+		clientset: linefake.NewSimpleClientset(),
+		recorder:  record.NewFakeRecorder(100),
+		config:    store,
+	}
+}
+
+func TestMakeDeploymentNgrokSidecar(t *testing.T) {
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "line-secret", Namespace: "default"},
+		Data: map[string][]byte{
+			constants.ChannelSecretKey: []byte("secret"),
+			constants.ChannelTokenKey:  []byte("token"),
+		},
+	}
+	c := newTestController(t, secret)
+
+	tests := []struct {
+		expose    linev1alpha1.BotExposeType
+		withNgrok bool
+	}{
+		{linev1alpha1.NgrokExpose, true},
+		{"", true},
+		{linev1alpha1.IngressExpose, false},
+		{linev1alpha1.LoadBalancerExpose, false},
+	}
+
+	for _, test := range tests {
+		bot := &linev1alpha1.Bot{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-bot", Namespace: "default"},
+			Spec: linev1alpha1.BotSpec{
+				ChannelSecretName: secret.Name,
+				Expose:            linev1alpha1.BotExpose{Type: test.expose},
+			},
+		}
+
+		d, err := c.makeDeployment(bot)
+		if err != nil {
+			t.Fatalf("expose %q: %v", test.expose, err)
+		}
+
+		pod := d.Spec.Template.Spec
+		hasContainer := false
+		for _, container := range pod.Containers {
+			if container.Name == "ngrok" {
+				hasContainer = true
+			}
+		}
+		hasVolume := false
+		for _, volume := range pod.Volumes {
+			if volume.Name == ngrokConfigVolumeName {
+				hasVolume = true
+			}
+		}
+
+		if hasContainer != test.withNgrok || hasVolume != test.withNgrok {
+			t.Errorf("expose %q: got ngrok container %v and volume %v, want %v",
+				test.expose, hasContainer, hasVolume, test.withNgrok)
+		}
+		if len(pod.Containers) == 0 || pod.Containers[0].Name != "linebot" {
+			t.Errorf("expose %q: missing the linebot container", test.expose)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package bot
+
+import (
+	"fmt"
+	"time"
+
+	linev1alpha1 "github.com/kairen/line-bot-operator/pkg/apis/line/v1alpha1"
+	"github.com/kairen/line-bot-operator/pkg/channeltoken"
+	"github.com/kairen/line-bot-operator/pkg/constants"
+	"github.com/kairen/line-bot-operator/pkg/k8sutil"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog"
+)
+
+const (
+	// previousChannelTokenKey keeps the replaced token until the bot has rolled out
+	// the new one, then it is revoked.
+	previousChannelTokenKey = "previousChannelToken"
+
+	defaultTokenLifetime = 30 * 24 * time.Hour
+)
+
+// syncChannelToken issues the channel access token of a bot with a managed token
+// source when it is missing or due for rotation, and revokes the replaced token.
+func (c *Controller) syncChannelToken(bot *linev1alpha1.Bot) error {
+	if !usesManagedToken(bot) {
+		return nil
+	}
+
+	secrets := c.ctx.Clientset.CoreV1().Secrets(bot.Namespace)
+	current, err := secrets.Get(channelTokenName(bot), metav1.GetOptions{})
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		current = nil
+	}
+
+	now := time.Now()
+	if current != nil {
+		if current, err = c.revokePreviousToken(bot, current); err != nil {
+			return err
+		}
+		if !tokenNeedsRotation(current, now) {
+			return nil
+		}
+
+		// The rotation replaces the previous token, so it waits for its revocation,
+		// the last third of the token lifetime leaves time for the rollout. Once the
+		// current token has expired, the older one has too.
+		if len(current.Data[previousChannelTokenKey]) > 0 && !tokenExpired(current, now) {
+			klog.V(2).Infof("Waiting for the revocation of the previous channel token to rotate %s in %s namespace.", bot.Name, bot.Namespace)
+			return nil
+		}
+	}
+
+	token, err := c.issueChannelToken(bot)
+	if err != nil {
+		c.recorder.Eventf(bot, v1.EventTypeWarning, constants.ReasonFailedIssue, "Failed to issue channel token: %v", err)
+		return err
+	}
+
+	secret := c.makeChannelTokenSecret(bot, token, now)
+	if current == nil {
+		if _, err := secrets.Create(secret); err != nil {
+			return err
+		}
+	} else {
+		// Keep the token being replaced until the bot has rolled out the new one.
+		secret.Data[previousChannelTokenKey] = current.Data[constants.ChannelTokenKey]
+		secret.ResourceVersion = current.ResourceVersion
+		if _, err := secrets.Update(secret); err != nil {
+			return err
+		}
+	}
+	c.recorder.Eventf(bot, v1.EventTypeNormal, constants.ReasonTokenIssued, "Issued channel token expiring at %s", token.ExpirationTime.Format(time.RFC3339))
+	klog.Infof("Success to issue channel token on %s in %s namespace.", bot.Name, bot.Namespace)
+
+	if current == nil {
+		return nil
+	}
+	return c.restartDeployment(bot, now)
+}
+
+func (c *Controller) issueChannelToken(bot *linev1alpha1.Bot) (*channeltoken.Token, error) {
+	source := bot.Spec.TokenSource
+	channelSecret, err := c.getChannelSecret(bot)
+	if err != nil {
+		return nil, err
+	}
+
+	switch source.Type {
+	case linev1alpha1.ClientSecretTokenSource:
+		return c.tokenClient().IssueShortLived(source.ChannelID, channelSecret)
+	case linev1alpha1.AssertionTokenSource:
+		secret, err := c.ctx.Clientset.CoreV1().Secrets(bot.Namespace).Get(source.PrivateKeySecretName, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+
+		key, err := channeltoken.ParsePrivateKey(secret.Data[constants.PrivateKeyKey])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the private key of secret %s: %v", secret.Name, err)
+		}
+
+		lifetime := defaultTokenLifetime
+		if source.TokenLifetime != nil {
+			lifetime = source.TokenLifetime.Duration
+		}
+
+		assertion, err := channeltoken.NewAssertion(source.ChannelID, string(secret.Data[constants.KeyIDKey]), key, lifetime)
+		if err != nil {
+			return nil, err
+		}
+		return c.tokenClient().IssueWithAssertion(assertion)
+	}
+	return nil, fmt.Errorf("unsupported token source %q", source.Type)
+}
+
+// revokePreviousToken revokes the replaced token once the deployment has rolled
+// out, so that no running pod still replies with it. It returns the secret, which
+// still holds the previous token while the rollout goes on.
+func (c *Controller) revokePreviousToken(bot *linev1alpha1.Bot, secret *v1.Secret) (*v1.Secret, error) {
+	previous := string(secret.Data[previousChannelTokenKey])
+	if previous == "" {
+		return secret, nil
+	}
+
+	d, err := c.ctx.Clientset.AppsV1().Deployments(bot.Namespace).Get(bot.Name, metav1.GetOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, err
+	}
+
+	if err == nil {
+		rolledOut := d.Status.ObservedGeneration >= d.Generation &&
+			d.Status.UpdatedReplicas == d.Status.Replicas &&
+			(d.Spec.Replicas == nil || d.Status.UpdatedReplicas == *d.Spec.Replicas)
+		if !rolledOut {
+			klog.V(2).Infof("Waiting for the rollout of %s in %s namespace to revoke the previous channel token.", bot.Name, bot.Namespace)
+			return secret, nil
+		}
+	}
+
+	if err := c.revokeChannelToken(bot, previous); err != nil {
+		// The token expires anyway, don't retry forever when it can't be revoked.
+		klog.Errorf("Failed to revoke the previous channel token on %s in %s namespace: %+v.", bot.Name, bot.Namespace, err)
+	} else {
+		c.recorder.Event(bot, v1.EventTypeNormal, constants.ReasonTokenRevoked, "Revoked the previous channel token")
+		klog.Infof("Success to revoke the previous channel token on %s in %s namespace.", bot.Name, bot.Namespace)
+	}
+
+	secret = secret.DeepCopy()
+	delete(secret.Data, previousChannelTokenKey)
+	return c.ctx.Clientset.CoreV1().Secrets(bot.Namespace).Update(secret)
+}
+
+func (c *Controller) revokeChannelToken(bot *linev1alpha1.Bot, token string) error {
+	if bot.Spec.TokenSource.Type != linev1alpha1.AssertionTokenSource {
+		return c.tokenClient().RevokeShortLived(token)
+	}
+
+	channelSecret, err := c.getChannelSecret(bot)
+	if err != nil {
+		return err
+	}
+	return c.tokenClient().Revoke(bot.Spec.TokenSource.ChannelID, channelSecret, token)
+}
+
+// tokenClient returns a client of the configured Messaging API.
+func (c *Controller) tokenClient() *channeltoken.Client {
+	client := channeltoken.NewClient()
+	client.BaseURL = c.config.Get().APIBaseURL
+	return client
+}
+
+func (c *Controller) getChannelSecret(bot *linev1alpha1.Bot) (string, error) {
+	secret, err := c.ctx.Clientset.CoreV1().Secrets(bot.Namespace).Get(bot.Spec.ChannelSecretName, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			c.recorder.Eventf(bot, v1.EventTypeWarning, constants.ReasonSecretNotFound,
+				"Channel secret %s not found", bot.Spec.ChannelSecretName)
+		}
+		return "", err
+	}
+	return string(secret.Data[constants.ChannelSecretKey]), nil
+}
+
+func (c *Controller) makeChannelTokenSecret(bot *linev1alpha1.Bot, token *channeltoken.Token, issued time.Time) *v1.Secret {
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      channelTokenName(bot),
+			Namespace: bot.Namespace,
+			Labels:    map[string]string{"bot": bot.Name},
+			Annotations: map[string]string{
+				constants.TokenIssuedAnnotation:     issued.Format(time.RFC3339),
+				constants.TokenExpirationAnnotation: token.ExpirationTime.Format(time.RFC3339),
+			},
+		},
+		Type: v1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			constants.ChannelTokenKey: []byte(token.AccessToken),
+		},
+	}
+
+	k8sutil.SetOwnerRef(c.ctx.Clientset, bot.Namespace, &secret.ObjectMeta, c.makeOnwerRefer(bot))
+	return secret
+}
+
+// restartDeployment rolls the bot pods by stamping the pod template, the same way
+// as kubectl rollout restart.
+func (c *Controller) restartDeployment(bot *linev1alpha1.Bot, at time.Time) error {
+	return c.patchPodTemplateAnnotation(bot, constants.RestartedAtAnnotation, at.Format(time.RFC3339))
+}
+
+// tokenNeedsRotation rotates the token once two thirds of its lifetime are over,
+// which leaves the rest of it to retry on failures.
+func tokenNeedsRotation(secret *v1.Secret, now time.Time) bool {
+	if len(secret.Data[constants.ChannelTokenKey]) == 0 {
+		return true
+	}
+
+	issued, err := time.Parse(time.RFC3339, secret.Annotations[constants.TokenIssuedAnnotation])
+	if err != nil {
+		return true
+	}
+
+	expiration, err := time.Parse(time.RFC3339, secret.Annotations[constants.TokenExpirationAnnotation])
+	if err != nil {
+		return true
+	}
+	return now.After(issued.Add(expiration.Sub(issued) * 2 / 3))
+}
+
+// tokenExpired is true when the expiration of the token is over or unknown.
+func tokenExpired(secret *v1.Secret, now time.Time) bool {
+	expiration, err := time.Parse(time.RFC3339, secret.Annotations[constants.TokenExpirationAnnotation])
+	return err != nil || !now.Before(expiration)
+}
+
+func usesManagedToken(bot *linev1alpha1.Bot) bool {
+	source := bot.Spec.TokenSource
+	return source != nil && source.Type != "" && source.Type != linev1alpha1.StaticTokenSource
+}
+
+func channelTokenName(bot *linev1alpha1.Bot) string {
+	return fmt.Sprintf("%s-channel-token", bot.Name)
+}
@@ -0,0 +1,185 @@
+package bot
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	linev1alpha1 "github.com/kairen/line-bot-operator/pkg/apis/line/v1alpha1"
+	"github.com/kairen/line-bot-operator/pkg/constants"
+	apps "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newTokenSecret(name, token string, issued, expiration time.Time) *v1.Secret {
+	return &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Annotations: map[string]string{
+				constants.TokenIssuedAnnotation:     issued.Format(time.RFC3339),
+				constants.TokenExpirationAnnotation: expiration.Format(time.RFC3339),
+			},
+		},
+		Data: map[string][]byte{constants.ChannelTokenKey: []byte(token)},
+	}
+}
+
+func TestTokenNeedsRotation(t *testing.T) {
+	issued := time.Date(2019, 4, 1, 0, 0, 0, 0, time.UTC)
+	expiration := issued.Add(30 * 24 * time.Hour)
+	secret := newTokenSecret("token", "token", issued, expiration)
+
+	noToken := secret.DeepCopy()
+	delete(noToken.Data, constants.ChannelTokenKey)
+	noIssued := secret.DeepCopy()
+	delete(noIssued.Annotations, constants.TokenIssuedAnnotation)
+	badExpiration := secret.DeepCopy()
+	badExpiration.Annotations[constants.TokenExpirationAnnotation] = "tomorrow"
+
+	tests := []struct {
+		name   string
+		secret *v1.Secret
+		now    time.Time
+		want   bool
+	}{
+		{"fresh", secret, issued.Add(time.Hour), false},
+		{"before two thirds", secret, issued.Add(19 * 24 * time.Hour), false},
+		{"at two thirds", secret, issued.Add(20 * 24 * time.Hour), false},
+		{"after two thirds", secret, issued.Add(20*24*time.Hour + time.Second), true},
+		{"expired", secret, expiration.Add(time.Hour), true},
+		{"no token", noToken, issued.Add(time.Hour), true},
+		{"no issue time", noIssued, issued.Add(time.Hour), true},
+		{"invalid expiration", badExpiration, issued.Add(time.Hour), true},
+	}
+
+	for _, test := range tests {
+		if got := tokenNeedsRotation(test.secret, test.now); got != test.want {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestSyncChannelTokenPendingRevocation(t *testing.T) {
+	issued, revoked := 0, []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/oauth/accessToken":
+			issued++
+			fmt.Fprintf(w, `{"access_token":"token-%d","expires_in":2592000}`, issued+2)
+		case "/v2/oauth/revoke":
+			revoked = append(revoked, r.PostFormValue("access_token"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	bot := &linev1alpha1.Bot{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-bot", Namespace: "default", UID: "test-bot-uid"},
+		Spec: linev1alpha1.BotSpec{
+			ChannelSecretName: "line-secret",
+			TokenSource:       &linev1alpha1.BotTokenSource{Type: linev1alpha1.ClientSecretTokenSource, ChannelID: "1234"},
+		},
+	}
+
+	// token-2 is due for rotation, while token-1 waits for the rollout of token-2.
+	now := time.Now()
+	current := newTokenSecret(channelTokenName(bot), "token-2", now.Add(-25*24*time.Hour), now.Add(5*24*time.Hour))
+	current.Data[previousChannelTokenKey] = []byte("token-1")
+	c := newTestController(t, current, &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "line-secret", Namespace: "default"},
+		Data:       map[string][]byte{constants.ChannelSecretKey: []byte("secret")},
+	})
+	c.config = newConfigStore(t, server.URL)
+
+	replicas := int32(1)
+	deployments := c.ctx.Clientset.AppsV1().Deployments(bot.Namespace)
+	d, err := deployments.Create(&apps.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: bot.Name, Namespace: bot.Namespace, Generation: 2},
+		Spec:       apps.DeploymentSpec{Replicas: &replicas},
+		Status:     apps.DeploymentStatus{ObservedGeneration: 1, Replicas: 2, UpdatedReplicas: 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tokens := func() (string, string) {
+		secret, err := c.ctx.Clientset.CoreV1().Secrets(bot.Namespace).Get(channelTokenName(bot), metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(secret.Data[constants.ChannelTokenKey]), string(secret.Data[previousChannelTokenKey])
+	}
+
+	// The rotation waits for the revocation of token-1.
+	if err := c.syncChannelToken(bot); err != nil {
+		t.Fatal(err)
+	}
+	if token, previous := tokens(); token != "token-2" || previous != "token-1" || issued != 0 {
+		t.Errorf("got token %s and previous %s after %d issues, want token-2 and token-1 kept", token, previous, issued)
+	}
+
+	d.Status = apps.DeploymentStatus{ObservedGeneration: 2, Replicas: 1, UpdatedReplicas: 1}
+	if _, err := deployments.Update(d); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.syncChannelToken(bot); err != nil {
+		t.Fatal(err)
+	}
+	if token, previous := tokens(); token != "token-3" || previous != "token-2" {
+		t.Errorf("got token %s and previous %s, want token-3 replacing token-2", token, previous)
+	}
+	if fmt.Sprint(revoked) != "[token-1]" {
+		t.Errorf("got revoked tokens %v, want token-1", revoked)
+	}
+}
+
+func TestSyncChannelTokenExpiredRotation(t *testing.T) {
+	issued := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		issued++
+		fmt.Fprint(w, `{"access_token":"token-3","expires_in":2592000}`)
+	}))
+	defer server.Close()
+
+	bot := &linev1alpha1.Bot{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-bot", Namespace: "default", UID: "test-bot-uid"},
+		Spec: linev1alpha1.BotSpec{
+			ChannelSecretName: "line-secret",
+			TokenSource:       &linev1alpha1.BotTokenSource{Type: linev1alpha1.ClientSecretTokenSource, ChannelID: "1234"},
+		},
+	}
+
+	// The rollout is stuck, and token-2 has expired.
+	now := time.Now()
+	current := newTokenSecret(channelTokenName(bot), "token-2", now.Add(-31*24*time.Hour), now.Add(-24*time.Hour))
+	current.Data[previousChannelTokenKey] = []byte("token-1")
+	c := newTestController(t, current, &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "line-secret", Namespace: "default"},
+		Data:       map[string][]byte{constants.ChannelSecretKey: []byte("secret")},
+	})
+	c.config = newConfigStore(t, server.URL)
+
+	if _, err := c.ctx.Clientset.AppsV1().Deployments(bot.Namespace).Create(&apps.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: bot.Name, Namespace: bot.Namespace, Generation: 2},
+		Status:     apps.DeploymentStatus{ObservedGeneration: 1},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.syncChannelToken(bot); err != nil {
+		t.Fatal(err)
+	}
+	secret, err := c.ctx.Clientset.CoreV1().Secrets(bot.Namespace).Get(channelTokenName(bot), metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token, previous := string(secret.Data[constants.ChannelTokenKey]), string(secret.Data[previousChannelTokenKey]); issued != 1 ||
+		token != "token-3" || previous != "token-2" {
+		t.Errorf("got token %s and previous %s after %d issues, want token-3 replacing token-2", token, previous, issued)
+	}
+}
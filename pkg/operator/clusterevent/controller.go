@@ -0,0 +1,88 @@
+package clusterevent
+
+import (
+	"reflect"
+
+	linev1alpha1 "github.com/kairen/line-bot-operator/pkg/apis/line/v1alpha1"
+	"github.com/kairen/line-bot-operator/pkg/constants"
+	clientset "github.com/kairen/line-bot-operator/pkg/generated/clientset/versioned"
+	opkit "github.com/kubedev/operator-kit"
+	v1 "k8s.io/api/core/v1"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog"
+)
+
+const (
+	customResourceName       = "clusterevent"
+	customResourceNamePlural = "clusterevents"
+)
+
+var Resource = opkit.CustomResource{
+	Name:    customResourceName,
+	Plural:  customResourceNamePlural,
+	Group:   linev1alpha1.CustomResourceGroup,
+	Version: linev1alpha1.Version,
+	Scope:   apiextensionsv1beta1.ClusterScoped,
+	Kind:    reflect.TypeOf(linev1alpha1.ClusterEvent{}).Name(),
+}
+
+// Controller warns about invalid ClusterEvent selectors. The subsets of the
+// eventbindings are computed by the eventbinding controller.
+type Controller struct {
+	ctx       *opkit.Context
+	clientset clientset.Interface
+	recorder  record.EventRecorder
+}
+
+func NewController(ctx *opkit.Context, clientset clientset.Interface, recorder record.EventRecorder) *Controller {
+	return &Controller{ctx: ctx, clientset: clientset, recorder: recorder}
+}
+
+func (c *Controller) StartWatch(stopCh chan struct{}) error {
+	resourceHandlerFuncs := cache.ResourceEventHandlerFuncs{
+		AddFunc:    c.onAdd,
+		UpdateFunc: c.onUpdate,
+		DeleteFunc: c.onDelete,
+	}
+
+	klog.Infof("Start watching clusterevent resources.")
+	watcher := opkit.NewWatcher(Resource, v1.NamespaceAll, resourceHandlerFuncs, c.clientset.LineV1alpha1().RESTClient())
+	go watcher.Watch(&linev1alpha1.ClusterEvent{}, stopCh)
+	return nil
+}
+
+func (c *Controller) onAdd(obj interface{}) {
+	event := obj.(*linev1alpha1.ClusterEvent).DeepCopy()
+	klog.V(2).Infof("Received onAdd on ClusterEvent %s.", event.Name)
+	c.checkSelectors(event)
+}
+
+func (c *Controller) onUpdate(oldObj, newObj interface{}) {
+	old := oldObj.(*linev1alpha1.ClusterEvent)
+	new := newObj.(*linev1alpha1.ClusterEvent).DeepCopy()
+	klog.V(2).Infof("Received onUpdate on ClusterEvent %s.", new.Name)
+
+	if old.Generation != new.Generation {
+		c.checkSelectors(new)
+	}
+}
+
+func (c *Controller) onDelete(obj interface{}) {
+	event, ok := obj.(*linev1alpha1.ClusterEvent)
+	if !ok {
+		return
+	}
+	klog.V(2).Infof("Received onDelete on ClusterEvent %s.", event.Name)
+}
+
+func (c *Controller) checkSelectors(event *linev1alpha1.ClusterEvent) {
+	for _, selector := range []*metav1.LabelSelector{event.Spec.Selector, event.Spec.NamespaceSelector} {
+		if _, err := metav1.LabelSelectorAsSelector(selector); err != nil {
+			c.recorder.Eventf(event, v1.EventTypeWarning, constants.ReasonFailedBinding, "Invalid selector: %v", err)
+			klog.Errorf("Failed to check selectors on ClusterEvent %s: %+v.", event.Name, err)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package dialog
+
+import (
+	"reflect"
+
+	linev1beta1 "github.com/kairen/line-bot-operator/pkg/apis/line/v1beta1"
+	"github.com/kairen/line-bot-operator/pkg/apis/line/validation"
+	"github.com/kairen/line-bot-operator/pkg/constants"
+	clientset "github.com/kairen/line-bot-operator/pkg/generated/clientset/versioned"
+	opkit "github.com/kubedev/operator-kit"
+	v1 "k8s.io/api/core/v1"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog"
+)
+
+const (
+	customResourceName       = "dialog"
+	customResourceNamePlural = "dialogs"
+)
+
+// Resource is only served as v1beta1, Dialogs have no v1alpha1 version.
+var Resource = opkit.CustomResource{
+	Name:    customResourceName,
+	Plural:  customResourceNamePlural,
+	Group:   linev1beta1.CustomResourceGroup,
+	Version: linev1beta1.Version,
+	Scope:   apiextensionsv1beta1.NamespaceScoped,
+	Kind:    reflect.TypeOf(linev1beta1.Dialog{}).Name(),
+}
+
+// Controller warns about invalid Dialogs, e.g. created while the admission webhook
+// was not installed. The dialogs are compiled into the rules by the eventbinding
+// controller.
+type Controller struct {
+	ctx       *opkit.Context
+	clientset clientset.Interface
+	recorder  record.EventRecorder
+}
+
+func NewController(ctx *opkit.Context, clientset clientset.Interface, recorder record.EventRecorder) *Controller {
+	return &Controller{ctx: ctx, clientset: clientset, recorder: recorder}
+}
+
+func (c *Controller) StartWatch(namespace string, stopCh chan struct{}) error {
+	resourceHandlerFuncs := cache.ResourceEventHandlerFuncs{
+		AddFunc:    c.onAdd,
+		UpdateFunc: c.onUpdate,
+		DeleteFunc: c.onDelete,
+	}
+
+	klog.Infof("Start watching dialog resources.")
+	watcher := opkit.NewWatcher(Resource, namespace, resourceHandlerFuncs, c.clientset.LineV1beta1().RESTClient())
+	go watcher.Watch(&linev1beta1.Dialog{}, stopCh)
+	return nil
+}
+
+func (c *Controller) onAdd(obj interface{}) {
+	dialog := obj.(*linev1beta1.Dialog).DeepCopy()
+	klog.V(2).Infof("Received onAdd on Dialog %s in %s namespace.", dialog.Name, dialog.Namespace)
+	c.checkSpec(dialog)
+}
+
+func (c *Controller) onUpdate(oldObj, newObj interface{}) {
+	old := oldObj.(*linev1beta1.Dialog)
+	new := newObj.(*linev1beta1.Dialog).DeepCopy()
+	klog.V(2).Infof("Received onUpdate on Dialog %s in %s namespace.", new.Name, new.Namespace)
+
+	if old.Generation != new.Generation {
+		c.checkSpec(new)
+	}
+}
+
+func (c *Controller) onDelete(obj interface{}) {
+	dialog, ok := obj.(*linev1beta1.Dialog)
+	if !ok {
+		return
+	}
+	klog.V(2).Infof("Received onDelete on Dialog %s in %s namespace.", dialog.Name, dialog.Namespace)
+}
+
+func (c *Controller) checkSpec(dialog *linev1beta1.Dialog) {
+	if errs := validation.ValidateDialogSpec(&dialog.Spec, field.NewPath("spec")); len(errs) > 0 {
+		c.recorder.Eventf(dialog, v1.EventTypeWarning, constants.ReasonFailedCompile, "Invalid dialog: %v", errs.ToAggregate())
+		klog.Errorf("Failed to check Dialog %s in %s namespace: %+v.", dialog.Name, dialog.Namespace, errs.ToAggregate())
+	}
+}
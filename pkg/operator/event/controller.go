@@ -1,18 +1,19 @@
 package event
 
 import (
-	"bytes"
-	"fmt"
 	"reflect"
-	"strings"
 
 	linev1alpha1 "github.com/kairen/line-bot-operator/pkg/apis/line/v1alpha1"
+	"github.com/kairen/line-bot-operator/pkg/constants"
 	clientset "github.com/kairen/line-bot-operator/pkg/generated/clientset/versioned"
 	opkit "github.com/kubedev/operator-kit"
-	slice "github.com/thoas/go-funk"
+	v1 "k8s.io/api/core/v1"
 	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog"
 )
 
@@ -33,10 +34,17 @@ var Resource = opkit.CustomResource{
 type Controller struct {
 	ctx       *opkit.Context
 	clientset clientset.Interface
+	recorder  record.EventRecorder
+	queue     workqueue.RateLimitingInterface
 }
 
-func NewController(ctx *opkit.Context, clientset clientset.Interface) *Controller {
-	return &Controller{ctx: ctx, clientset: clientset}
+func NewController(ctx *opkit.Context, clientset clientset.Interface, recorder record.EventRecorder) *Controller {
+	return &Controller{
+		ctx:       ctx,
+		clientset: clientset,
+		recorder:  recorder,
+		queue:     workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), customResourceNamePlural),
+	}
 }
 
 func (c *Controller) StartWatch(namespace string, stopCh chan struct{}) error {
@@ -49,6 +57,8 @@ func (c *Controller) StartWatch(namespace string, stopCh chan struct{}) error {
 	klog.Infof("Start watching event resources.")
 	watcher := opkit.NewWatcher(Resource, namespace, resourceHandlerFuncs, c.clientset.LineV1alpha1().RESTClient())
 	go watcher.Watch(&linev1alpha1.Event{}, stopCh)
+	go c.watchEventBindings(namespace, stopCh)
+	go c.runWorker(stopCh)
 	return nil
 }
 
@@ -56,128 +66,123 @@ func (c *Controller) onAdd(obj interface{}) {
 	event := obj.(*linev1alpha1.Event).DeepCopy()
 	klog.V(2).Infof("Received onAdd on Event %s in %s namespace.", event.Name, event.Namespace)
 
-	if event.Spec.Selector != nil {
-		if err := c.updateNewToBinding(event); err != nil {
-			klog.Errorf("Failed to update eventbind on %s in %s namespace: %+v.", event.Name, event.Namespace, err)
-		}
+	if err := c.checkSelector(event); err != nil {
+		klog.Errorf("Failed to check selector on %s in %s namespace: %+v.", event.Name, event.Namespace, err)
 	}
+	c.enqueue(event.Namespace, event.Name)
 }
 
 func (c *Controller) onUpdate(oldObj, newObj interface{}) {
+	old := oldObj.(*linev1alpha1.Event)
 	new := newObj.(*linev1alpha1.Event).DeepCopy()
 	klog.V(2).Infof("Received onUpdate on Event %s in %s namespace.", new.Name, new.Namespace)
 
-	if new.Spec.Selector != nil {
-		if err := c.updateChangeToBinding(new); err != nil {
-			klog.Errorf("Failed to update eventbind on %s in %s namespace: %+v.", new.Name, new.Namespace, err)
+	// Status updates don't change the spec, so the warning isn't recorded again.
+	if old.Generation != new.Generation || !reflect.DeepEqual(old.Spec.Selector, new.Spec.Selector) {
+		if err := c.checkSelector(new); err != nil {
+			klog.Errorf("Failed to check selector on %s in %s namespace: %+v.", new.Name, new.Namespace, err)
 		}
 	}
+	c.enqueue(new.Namespace, new.Name)
 }
 
 func (c *Controller) onDelete(obj interface{}) {
-	event := obj.(*linev1alpha1.Event).DeepCopy()
-	klog.V(2).Infof("Received onDelete on Event %s in %s namespace.", event.Name, event.Namespace)
-
-	if event.Spec.Selector != nil {
-		if err := c.updateDeleteToBinding(event); err != nil {
-			klog.Errorf("Failed to update eventbind on %s in %s namespace: %+v.", event.Name, event.Namespace, err)
-		}
+	event, ok := obj.(*linev1alpha1.Event)
+	if !ok {
+		return
 	}
+	klog.V(2).Infof("Received onDelete on Event %s in %s namespace.", event.Name, event.Namespace)
 }
 
-func (c *Controller) getEventBindingList(event *linev1alpha1.Event) (*linev1alpha1.EventBindingList, error) {
-	return c.clientset.LineV1alpha1().EventBindings(event.Namespace).List(metav1.ListOptions{
-		LabelSelector: c.createKeyValuePairs(event.Spec.Selector.MatchLabels),
+// watchEventBindings queues the events added to or removed from the subsets of an
+// eventbinding, their bindings and keyword conflicts are shown in the status.
+func (c *Controller) watchEventBindings(namespace string, stopCh chan struct{}) {
+	source := cache.NewListWatchFromClient(c.clientset.LineV1alpha1().RESTClient(), "eventbindings", namespace, fields.Everything())
+	_, controller := cache.NewInformer(source, &linev1alpha1.EventBinding{}, 0, cache.ResourceEventHandlerFuncs{
+		AddFunc: c.enqueueSubsets,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			c.enqueueSubsets(oldObj)
+			c.enqueueSubsets(newObj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			c.enqueueSubsets(obj)
+		},
 	})
+	controller.Run(stopCh)
 }
 
-func (c *Controller) updateNewToBinding(event *linev1alpha1.Event) error {
-	eventBindings, err := c.getEventBindingList(event)
-	if err != nil {
-		return err
+func (c *Controller) enqueueSubsets(obj interface{}) {
+	eventBinding, ok := obj.(*linev1alpha1.EventBinding)
+	if !ok {
+		return
 	}
 
-	subset := linev1alpha1.EventBindingSubset{
-		Binding: linev1alpha1.Binding{
-			Name:     event.Name,
-			Type:     event.Spec.Type,
-			Messages: event.Spec.Messages,
-		},
+	for _, subset := range eventBinding.Subsets {
+		c.enqueue(eventBinding.Namespace, subset.Binding.Name)
 	}
-	for _, eventBinding := range eventBindings.Items {
-		if !slice.Contains(eventBinding.Subsets, subset) {
-			eventBinding.Subsets = append(eventBinding.Subsets, subset)
-		}
+}
 
-		_, err := c.clientset.LineV1alpha1().EventBindings(event.Namespace).Update(&eventBinding)
-		if err != nil {
-			return err
-		}
+func (c *Controller) enqueue(namespace, name string) {
+	c.queue.Add(namespace + "/" + name)
+}
+
+func (c *Controller) runWorker(stopCh chan struct{}) {
+	go func() {
+		<-stopCh
+		c.queue.ShutDown()
+	}()
+
+	for c.processNextItem() {
 	}
-	return nil
 }
 
-func (c *Controller) updateChangeToBinding(event *linev1alpha1.Event) error {
-	eventBindings, err := c.getEventBindingList(event)
-	if err != nil {
-		return err
+func (c *Controller) processNextItem() bool {
+	key, quit := c.queue.Get()
+	if quit {
+		return false
 	}
+	defer c.queue.Done(key)
 
-	subset := linev1alpha1.EventBindingSubset{
-		Binding: linev1alpha1.Binding{
-			Name:     event.Name,
-			Type:     event.Spec.Type,
-			Messages: event.Spec.Messages,
-		},
+	recheck, err := c.syncStatus(key.(string))
+	if err != nil {
+		klog.Errorf("Failed to sync event %s: %+v.", key, err)
+		c.queue.AddRateLimited(key)
+		return true
 	}
+	c.queue.Forget(key)
 
-	for _, eventBinding := range eventBindings.Items {
-		result := slice.Filter(eventBinding.Subsets, func(sb linev1alpha1.EventBindingSubset) bool {
-			return sb.Binding.Name != subset.Binding.Name
-		})
-		eventBinding.Subsets = result.([]linev1alpha1.EventBindingSubset)
-		eventBinding.Subsets = append(eventBinding.Subsets, subset)
-		_, err := c.clientset.LineV1alpha1().EventBindings(event.Namespace).Update(&eventBinding)
-		if err != nil {
-			return err
-		}
+	// Scheduled events are checked again when they switch on or off.
+	if recheck > 0 {
+		c.queue.AddAfter(key, recheck)
 	}
-	return nil
+	return true
 }
 
-func (c *Controller) updateDeleteToBinding(event *linev1alpha1.Event) error {
-	eventBindings, err := c.getEventBindingList(event)
+// checkSelector warns about an event that selects no bots. The subsets of the
+// eventbindings are computed by the eventbinding controller.
+func (c *Controller) checkSelector(event *linev1alpha1.Event) error {
+	if event.Spec.Selector == nil {
+		return nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(event.Spec.Selector)
 	if err != nil {
+		c.recorder.Eventf(event, v1.EventTypeWarning, constants.ReasonFailedBinding, "Invalid selector: %v", err)
 		return err
 	}
 
-	subset := linev1alpha1.EventBindingSubset{
-		Binding: linev1alpha1.Binding{
-			Name:     event.Name,
-			Type:     event.Spec.Type,
-			Messages: event.Spec.Messages,
-		},
+	eventBindings, err := c.clientset.LineV1alpha1().EventBindings(event.Namespace).List(metav1.ListOptions{
+		LabelSelector: selector.String(),
+	})
+	if err != nil {
+		return err
 	}
 
-	for _, eventBinding := range eventBindings.Items {
-		if slice.Contains(eventBinding.Subsets, subset) {
-			result := slice.Filter(eventBinding.Subsets, func(sb linev1alpha1.EventBindingSubset) bool {
-				return sb.Binding.Name != subset.Binding.Name
-			})
-			eventBinding.Subsets = result.([]linev1alpha1.EventBindingSubset)
-		}
-		_, err := c.clientset.LineV1alpha1().EventBindings(event.Namespace).Update(&eventBinding)
-		if err != nil {
-			return err
-		}
+	if len(eventBindings.Items) == 0 {
+		c.recorder.Eventf(event, v1.EventTypeWarning, constants.ReasonNoBotSelected, "Selector %s matches no bots", selector.String())
 	}
 	return nil
 }
-
-func (c *Controller) createKeyValuePairs(m map[string]string) string {
-	b := new(bytes.Buffer)
-	for key, value := range m {
-		fmt.Fprintf(b, "%s=%s,", key, value)
-	}
-	return strings.TrimSuffix(b.String(), ",")
-}
@@ -0,0 +1,185 @@
+package event
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	linev1alpha1 "github.com/kairen/line-bot-operator/pkg/apis/line/v1alpha1"
+	"github.com/kairen/line-bot-operator/pkg/apis/line/validation"
+	"github.com/kairen/line-bot-operator/pkg/constants"
+	"github.com/kairen/line-bot-operator/pkg/schedule"
+	"github.com/line/line-bot-sdk-go/linebot"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+)
+
+// maxRecheck bounds the time before a scheduled event is checked again, so that
+// the changes of its holidays ConfigMap show up in the status.
+const maxRecheck = time.Hour
+
+// syncStatus writes the status computed from the event spec and the eventbindings
+// holding it, the status is only updated when it changes. It returns when to sync
+// a scheduled event again.
+func (c *Controller) syncStatus(key string) (time.Duration, error) {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return 0, err
+	}
+
+	events := c.clientset.LineV1alpha1().Events(namespace)
+	event, err := events.Get(name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	eventBindings, err := c.clientset.LineV1alpha1().EventBindings(namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return 0, err
+	}
+
+	status := computeStatus(event, eventBindings.Items)
+	recheck, err := c.checkSchedule(event, &status, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	if reflect.DeepEqual(event.Status, status) {
+		return recheck, nil
+	}
+
+	event.Status = status
+	if _, err := events.UpdateStatus(event); err != nil {
+		// Without the status subresource of deploy/crd.yml, a full update would bump
+		// the generation and sync the event again forever, so the status is skipped.
+		if errors.IsNotFound(err) {
+			klog.Errorf("Failed to update status on %s in %s namespace, the CRD has no status subresource: %+v.", name, namespace, err)
+			return recheck, nil
+		}
+		return 0, err
+	}
+	klog.V(2).Infof("Success to update status on %s in %s namespace.", name, namespace)
+	return recheck, nil
+}
+
+// checkSchedule sets whether the schedule of the event is active now and returns
+// when it may change. An event whose schedule is invalid or whose holidays can't
+// be read is inactive, as the bots leave it out of their rules.
+func (c *Controller) checkSchedule(event *linev1alpha1.Event, status *linev1alpha1.EventStatus, now time.Time) (time.Duration, error) {
+	spec := event.Spec.Schedule
+	status.Active = spec == nil
+	if spec == nil {
+		return 0, nil
+	}
+
+	s := &schedule.Spec{
+		TimeZone:  spec.TimeZone,
+		Windows:   spec.Windows,
+		StartDate: spec.StartDate,
+		EndDate:   spec.EndDate,
+	}
+	if holidays := spec.Holidays; holidays != nil {
+		s.Holidays = holidays.Dates
+		s.ActiveOnHolidays = holidays.Active
+		if holidays.ConfigMapName != "" {
+			cm, err := c.ctx.Clientset.CoreV1().ConfigMaps(event.Namespace).Get(holidays.ConfigMapName, metav1.GetOptions{})
+			if err != nil {
+				if !errors.IsNotFound(err) {
+					return 0, err
+				}
+				status.Errors = append(status.Errors, fmt.Sprintf("spec.schedule.holidays.configMapName: %v", err))
+				return maxRecheck, nil
+			}
+			for _, line := range strings.Split(cm.Data[constants.HolidaysKey], "\n") {
+				if date := strings.TrimSpace(line); date != "" {
+					s.Holidays = append(s.Holidays, date)
+				}
+			}
+		}
+	}
+
+	compiled, err := schedule.New(s)
+	if err != nil {
+		// Invalid schedules are already in the validation errors.
+		return 0, nil
+	}
+
+	status.Active = compiled.Active(now)
+	if next, ok := compiled.NextChange(now, maxRecheck); ok {
+		return next.Sub(now), nil
+	}
+	return maxRecheck, nil
+}
+
+// computeStatus returns the status of an event, the conflicts are the keywords of
+// the event also used by another subset of the same eventbinding, and are flagged
+// when the resolution order can't tell the subsets apart.
+func computeStatus(event *linev1alpha1.Event, eventBindings []linev1alpha1.EventBinding) linev1alpha1.EventStatus {
+	status := linev1alpha1.EventStatus{ObservedGeneration: event.Generation}
+	for _, err := range validation.ValidateEventSpec(&event.Spec, field.NewPath("spec")) {
+		status.Errors = append(status.Errors, err.Error())
+	}
+
+	keywords := map[string]bool{}
+	if event.Spec.Type == linebot.EventTypeMessage {
+		for _, msg := range event.Spec.Messages {
+			for _, keyword := range msg.Keywords {
+				keywords[keyword] = true
+			}
+		}
+	}
+
+	sort.Slice(eventBindings, func(i, j int) bool {
+		return eventBindings[i].Name < eventBindings[j].Name
+	})
+
+	binding := &linev1alpha1.Binding{
+		Name:     event.Name,
+		Type:     event.Spec.Type,
+		Messages: event.Spec.Messages,
+		Priority: event.Spec.Priority,
+	}
+
+	for _, eventBinding := range eventBindings {
+		if !hasSubset(&eventBinding, event.Name) {
+			continue
+		}
+		status.Bindings = append(status.Bindings, eventBinding.Name)
+
+		for _, subset := range eventBinding.Subsets {
+			if subset.Binding.Refers(linev1alpha1.EventOrigin, event.Name) || subset.Binding.Type != linebot.EventTypeMessage {
+				continue
+			}
+
+			for _, msg := range subset.Binding.Messages {
+				for _, keyword := range msg.Keywords {
+					if keywords[keyword] {
+						status.Conflicts = append(status.Conflicts, linev1alpha1.EventConflict{
+							Keyword: keyword,
+							Event:   subset.Binding.Reference(),
+							Bot:     eventBinding.Name,
+							Tie:     linev1alpha1.BindingTies(binding, &subset.Binding),
+						})
+					}
+				}
+			}
+		}
+	}
+	return status
+}
+
+func hasSubset(eventBinding *linev1alpha1.EventBinding, name string) bool {
+	for _, subset := range eventBinding.Subsets {
+		if subset.Binding.Refers(linev1alpha1.EventOrigin, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -4,10 +4,18 @@ import (
 	"reflect"
 
 	linev1alpha1 "github.com/kairen/line-bot-operator/pkg/apis/line/v1alpha1"
+	linev1beta1 "github.com/kairen/line-bot-operator/pkg/apis/line/v1beta1"
 	clientset "github.com/kairen/line-bot-operator/pkg/generated/clientset/versioned"
+	"github.com/kairen/line-bot-operator/pkg/operator/clusterevent"
+	"github.com/kairen/line-bot-operator/pkg/operator/dialog"
+	"github.com/kairen/line-bot-operator/pkg/operator/event"
 	opkit "github.com/kubedev/operator-kit"
+	v1 "k8s.io/api/core/v1"
 	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog"
 )
 
@@ -25,13 +33,26 @@ var Resource = opkit.CustomResource{
 	Kind:    reflect.TypeOf(linev1alpha1.EventBinding{}).Name(),
 }
 
+// Controller is the only writer of the eventbinding subsets. It recomputes the
+// subsets of an eventbinding from all the events selecting it, so that concurrent
+// event changes can't overwrite each other.
 type Controller struct {
 	ctx       *opkit.Context
 	clientset clientset.Interface
+	recorder  record.EventRecorder
+	queue     workqueue.RateLimitingInterface
+	// eventBindings caches the eventbindings by namespace and by the ConfigMaps
+	// they read, see watchEventBindings.
+	eventBindings cache.Indexer
 }
 
-func NewController(ctx *opkit.Context, clientset clientset.Interface) *Controller {
-	return &Controller{ctx: ctx, clientset: clientset}
+func NewController(ctx *opkit.Context, clientset clientset.Interface, recorder record.EventRecorder) *Controller {
+	return &Controller{
+		ctx:       ctx,
+		clientset: clientset,
+		recorder:  recorder,
+		queue:     workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), customResourceNamePlural),
+	}
 }
 
 func (c *Controller) StartWatch(namespace string, stopCh chan struct{}) error {
@@ -41,23 +62,311 @@ func (c *Controller) StartWatch(namespace string, stopCh chan struct{}) error {
 		DeleteFunc: c.onDelete,
 	}
 
+	eventHandlerFuncs := cache.ResourceEventHandlerFuncs{
+		AddFunc:    c.onEventAdd,
+		UpdateFunc: c.onEventUpdate,
+		DeleteFunc: c.onEventDelete,
+	}
+
 	klog.Infof("Start watching eventbinding resources.")
+	c.watchEventBindings(namespace, stopCh)
 	watcher := opkit.NewWatcher(Resource, namespace, resourceHandlerFuncs, c.clientset.LineV1alpha1().RESTClient())
 	go watcher.Watch(&linev1alpha1.EventBinding{}, stopCh)
+
+	eventWatcher := opkit.NewWatcher(event.Resource, namespace, eventHandlerFuncs, c.clientset.LineV1alpha1().RESTClient())
+	go eventWatcher.Watch(&linev1alpha1.Event{}, stopCh)
+
+	clusterEventHandlerFuncs := cache.ResourceEventHandlerFuncs{
+		AddFunc:    c.onClusterEventAdd,
+		UpdateFunc: c.onClusterEventUpdate,
+		DeleteFunc: c.onClusterEventDelete,
+	}
+	clusterEventWatcher := opkit.NewWatcher(clusterevent.Resource, v1.NamespaceAll, clusterEventHandlerFuncs, c.clientset.LineV1alpha1().RESTClient())
+	go clusterEventWatcher.Watch(&linev1alpha1.ClusterEvent{}, stopCh)
+
+	dialogHandlerFuncs := cache.ResourceEventHandlerFuncs{
+		AddFunc: c.enqueueForDialog,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			c.enqueueForDialog(oldObj)
+			c.enqueueForDialog(newObj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			c.enqueueForDialog(obj)
+		},
+	}
+	dialogWatcher := opkit.NewWatcher(dialog.Resource, namespace, dialogHandlerFuncs, c.clientset.LineV1beta1().RESTClient())
+	go dialogWatcher.Watch(&linev1beta1.Dialog{}, stopCh)
+	go c.watchNamespaces(stopCh)
+	go c.watchConfigMaps(namespace, stopCh)
+	go c.runWorker(stopCh)
 	return nil
 }
 
 func (c *Controller) onAdd(obj interface{}) {
-	eventbind := obj.(*linev1alpha1.EventBinding).DeepCopy()
+	eventbind := obj.(*linev1alpha1.EventBinding)
 	klog.V(2).Infof("Received onAdd on EventBinding %s in %s namespace.", eventbind.Name, eventbind.Namespace)
+	c.enqueue(eventbind)
 }
 
 func (c *Controller) onUpdate(oldObj, newObj interface{}) {
-	new := newObj.(*linev1alpha1.EventBinding).DeepCopy()
+	new := newObj.(*linev1alpha1.EventBinding)
 	klog.V(2).Infof("Received onUpdate on EventBinding %s in %s namespace.", new.Name, new.Namespace)
+	c.enqueue(new)
 }
 
 func (c *Controller) onDelete(obj interface{}) {
-	eventbind := obj.(*linev1alpha1.EventBinding).DeepCopy()
+	eventbind, ok := obj.(*linev1alpha1.EventBinding)
+	if !ok {
+		return
+	}
 	klog.V(2).Infof("Received onDelete on EventBinding %s in %s namespace.", eventbind.Name, eventbind.Namespace)
 }
+
+func (c *Controller) onEventAdd(obj interface{}) {
+	c.enqueueForEvent(obj.(*linev1alpha1.Event))
+}
+
+// onEventUpdate also queues the eventbindings selected by the old event, which
+// have to drop its subset when the selector no longer matches them.
+func (c *Controller) onEventUpdate(oldObj, newObj interface{}) {
+	old, new := oldObj.(*linev1alpha1.Event), newObj.(*linev1alpha1.Event)
+	if !reflect.DeepEqual(old.Spec.Selector, new.Spec.Selector) {
+		c.enqueueForEvent(old)
+	}
+	c.enqueueForEvent(new)
+}
+
+func (c *Controller) onEventDelete(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	if event, ok := obj.(*linev1alpha1.Event); ok {
+		c.enqueueForEvent(event)
+	}
+}
+
+func (c *Controller) enqueue(eventBinding *linev1alpha1.EventBinding) {
+	key, err := cache.MetaNamespaceKeyFunc(eventBinding)
+	if err != nil {
+		klog.Errorf("Failed to get the key of EventBinding %s: %+v.", eventBinding.Name, err)
+		return
+	}
+	c.queue.Add(key)
+}
+
+// enqueueForEvent queues the eventbindings selected by an event, and the ones
+// still holding its subset.
+func (c *Controller) enqueueForEvent(event *linev1alpha1.Event) {
+	for _, eventBinding := range c.cachedEventBindings(event.Namespace) {
+		if selects(event.Spec.Selector, eventBinding.Labels) || hasSubset(eventBinding, linev1alpha1.EventOrigin, event.Name) {
+			c.enqueue(eventBinding)
+		}
+	}
+}
+
+func (c *Controller) onClusterEventAdd(obj interface{}) {
+	c.enqueueForClusterEvent(obj.(*linev1alpha1.ClusterEvent))
+}
+
+func (c *Controller) onClusterEventUpdate(oldObj, newObj interface{}) {
+	old, new := oldObj.(*linev1alpha1.ClusterEvent), newObj.(*linev1alpha1.ClusterEvent)
+	if !reflect.DeepEqual(old.Spec.Selector, new.Spec.Selector) {
+		c.enqueueForClusterEvent(old)
+	}
+	c.enqueueForClusterEvent(new)
+}
+
+func (c *Controller) onClusterEventDelete(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	if event, ok := obj.(*linev1alpha1.ClusterEvent); ok {
+		c.enqueueForClusterEvent(event)
+	}
+}
+
+// enqueueForClusterEvent queues the eventbindings of all the namespaces selected
+// by the labels of a ClusterEvent, the namespace selector is checked by sync.
+func (c *Controller) enqueueForClusterEvent(event *linev1alpha1.ClusterEvent) {
+	for _, eventBinding := range c.cachedEventBindings(v1.NamespaceAll) {
+		if matches(event.Spec.Selector, eventBinding.Labels) || hasSubset(eventBinding, linev1alpha1.ClusterEventOrigin, event.Name) {
+			c.enqueue(eventBinding)
+		}
+	}
+}
+
+// enqueueForDialog queues the eventbindings selected by a dialog, to compile it
+// into their rules.
+func (c *Controller) enqueueForDialog(obj interface{}) {
+	dialog, ok := obj.(*linev1beta1.Dialog)
+	if !ok {
+		return
+	}
+
+	for _, eventBinding := range c.cachedEventBindings(dialog.Namespace) {
+		if selects(dialog.Spec.Selector, eventBinding.Labels) {
+			c.enqueue(eventBinding)
+		}
+	}
+}
+
+// watchNamespaces queues the eventbindings of a namespace whose labels change,
+// because they may be selected by other ClusterEvents.
+func (c *Controller) watchNamespaces(stopCh chan struct{}) {
+	source := cache.NewListWatchFromClient(c.ctx.Clientset.CoreV1().RESTClient(), "namespaces", v1.NamespaceAll, fields.Everything())
+	_, controller := cache.NewInformer(source, &v1.Namespace{}, 0, cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			old, new := oldObj.(*v1.Namespace), newObj.(*v1.Namespace)
+			if !reflect.DeepEqual(old.Labels, new.Labels) {
+				c.enqueueNamespace(new.Name)
+			}
+		},
+	})
+	controller.Run(stopCh)
+}
+
+// watchConfigMaps queues the eventbindings with subsets reading their allow and
+// deny lists, their holidays or their translations from a changed ConfigMap.
+func (c *Controller) watchConfigMaps(namespace string, stopCh chan struct{}) {
+	source := cache.NewListWatchFromClient(c.ctx.Clientset.CoreV1().RESTClient(), "configmaps", namespace, fields.Everything())
+	_, controller := cache.NewInformer(source, &v1.ConfigMap{}, 0, cache.ResourceEventHandlerFuncs{
+		AddFunc: c.enqueueForConfigMap,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			if !reflect.DeepEqual(oldObj.(*v1.ConfigMap).Data, newObj.(*v1.ConfigMap).Data) {
+				c.enqueueForConfigMap(newObj)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			c.enqueueForConfigMap(obj)
+		},
+	})
+	controller.Run(stopCh)
+}
+
+// configMapIndex indexes the cached eventbindings by the namespaced names of the
+// ConfigMaps read by their subsets.
+const configMapIndex = "configMap"
+
+// watchEventBindings caches the eventbindings, so that the changes of the events,
+// dialogs and ConfigMaps find their eventbindings without listing them from the
+// API server, which could fail and drop the change.
+func (c *Controller) watchEventBindings(namespace string, stopCh chan struct{}) {
+	source := cache.NewListWatchFromClient(c.clientset.LineV1alpha1().RESTClient(), customResourceNamePlural, namespace, fields.Everything())
+	indexer, controller := cache.NewIndexerInformer(source, &linev1alpha1.EventBinding{}, 0, cache.ResourceEventHandlerFuncs{}, indexers)
+	c.eventBindings = indexer
+	go controller.Run(stopCh)
+}
+
+var indexers = cache.Indexers{
+	cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
+	configMapIndex:       indexConfigMaps,
+}
+
+func indexConfigMaps(obj interface{}) ([]string, error) {
+	eventBinding, ok := obj.(*linev1alpha1.EventBinding)
+	if !ok {
+		return nil, nil
+	}
+
+	keys := []string{}
+	seen := map[string]bool{}
+	for i := range eventBinding.Subsets {
+		for _, name := range configMapNames(&eventBinding.Subsets[i].Binding) {
+			key := eventBinding.Namespace + "/" + name
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys, nil
+}
+
+func (c *Controller) enqueueForConfigMap(obj interface{}) {
+	cm, ok := obj.(*v1.ConfigMap)
+	if !ok {
+		return
+	}
+
+	eventBindings, err := c.eventBindings.ByIndex(configMapIndex, cm.Namespace+"/"+cm.Name)
+	if err != nil {
+		klog.Errorf("Failed to find eventbindings for configmap %s in %s namespace: %+v.", cm.Name, cm.Namespace, err)
+		return
+	}
+
+	for _, obj := range eventBindings {
+		c.enqueue(obj.(*linev1alpha1.EventBinding))
+	}
+}
+
+// configMapNames returns the ConfigMaps read by a binding for its allow and deny
+// lists, its holidays and its translations.
+func configMapNames(binding *linev1alpha1.Binding) []string {
+	names := []string{}
+	if source := binding.Source; source != nil && source.ConfigMapName != "" {
+		names = append(names, source.ConfigMapName)
+	}
+	if schedule := binding.Schedule; schedule != nil && schedule.Holidays != nil && schedule.Holidays.ConfigMapName != "" {
+		names = append(names, schedule.Holidays.ConfigMapName)
+	}
+	for _, msg := range binding.Messages {
+		if msg.I18n != nil && msg.I18n.ConfigMapName != "" {
+			names = append(names, msg.I18n.ConfigMapName)
+		}
+	}
+	return names
+}
+
+func (c *Controller) enqueueNamespace(namespace string) {
+	for _, eventBinding := range c.cachedEventBindings(namespace) {
+		c.enqueue(eventBinding)
+	}
+}
+
+// cachedEventBindings returns the cached eventbindings of a namespace, or of all
+// the namespaces.
+func (c *Controller) cachedEventBindings(namespace string) []*linev1alpha1.EventBinding {
+	objs := c.eventBindings.List()
+	if namespace != v1.NamespaceAll {
+		// The namespace index can't fail, its index function never returns an error.
+		objs, _ = c.eventBindings.ByIndex(cache.NamespaceIndex, namespace)
+	}
+
+	eventBindings := make([]*linev1alpha1.EventBinding, 0, len(objs))
+	for _, obj := range objs {
+		eventBindings = append(eventBindings, obj.(*linev1alpha1.EventBinding))
+	}
+	return eventBindings
+}
+
+func (c *Controller) runWorker(stopCh chan struct{}) {
+	go func() {
+		<-stopCh
+		c.queue.ShutDown()
+	}()
+
+	for c.processNextItem() {
+	}
+}
+
+func (c *Controller) processNextItem() bool {
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(key)
+
+	if err := c.sync(key.(string)); err != nil {
+		klog.Errorf("Failed to sync eventbinding %s: %+v.", key, err)
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	c.queue.Forget(key)
+	return true
+}
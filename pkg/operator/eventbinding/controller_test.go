@@ -0,0 +1,181 @@
+package eventbinding
+
+import (
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	linev1alpha1 "github.com/kairen/line-bot-operator/pkg/apis/line/v1alpha1"
+	clientset "github.com/kairen/line-bot-operator/pkg/generated/clientset/versioned"
+	"github.com/kairen/line-bot-operator/pkg/generated/clientset/versioned/fake"
+	"github.com/kairen/line-bot-operator/pkg/generated/clientset/versioned/scheme"
+	linev1alpha1client "github.com/kairen/line-bot-operator/pkg/generated/clientset/versioned/typed/line/v1alpha1"
+	opkit "github.com/kubedev/operator-kit"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+)
+
+const testNamespace = "default"
+
+// newFakeClientset returns a fake clientset rejecting the eventbinding updates
+// with a stale resource version, like the API server does.
+func newFakeClientset(t *testing.T, objects ...runtime.Object) *fake.Clientset {
+	tracker := k8stesting.NewObjectTracker(scheme.Scheme, scheme.Codecs.UniversalDecoder())
+	for _, obj := range objects {
+		if err := tracker.Add(obj); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cs := &fake.Clientset{}
+	cs.AddReactor("update", "eventbindings", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		gvr := action.GetResource()
+		desired := action.(k8stesting.UpdateAction).GetObject().(*linev1alpha1.EventBinding).DeepCopy()
+		current, err := tracker.Get(gvr, action.GetNamespace(), desired.Name)
+		if err != nil {
+			return true, nil, err
+		}
+
+		version := current.(*linev1alpha1.EventBinding).ResourceVersion
+		if desired.ResourceVersion != version {
+			return true, nil, errors.NewConflict(gvr.GroupResource(), desired.Name,
+				fmt.Errorf("resource version %s is stale", desired.ResourceVersion))
+		}
+
+		n, _ := strconv.Atoi(version)
+		desired.ResourceVersion = strconv.Itoa(n + 1)
+		return true, desired, tracker.Update(gvr, desired, action.GetNamespace())
+	})
+	cs.AddReactor("*", "*", k8stesting.ObjectReaction(tracker))
+	return cs
+}
+
+func newTestController(client clientset.Interface, eventBindings ...*linev1alpha1.EventBinding) *Controller {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, indexers)
+	for _, eventBinding := range eventBindings {
+		indexer.Add(eventBinding)
+	}
+
+	ns := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: testNamespace}}
+	return &Controller{
+		ctx:           &opkit.Context{Clientset: kubefake.NewSimpleClientset(ns)},
+		clientset:     client,
+		recorder:      record.NewFakeRecorder(100),
+		queue:         workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+		eventBindings: indexer,
+	}
+}
+
+func newEventBinding(name string, labels map[string]string) *linev1alpha1.EventBinding {
+	return &linev1alpha1.EventBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       testNamespace,
+			Labels:          labels,
+			ResourceVersion: "1",
+		},
+	}
+}
+
+func newEvent(name string, matchLabels map[string]string) *linev1alpha1.Event {
+	return &linev1alpha1.Event{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+		Spec: linev1alpha1.EventSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: matchLabels},
+			Type:     "message",
+			Messages: []linev1alpha1.Message{{Type: "text", Keywords: []string{name}, Reply: name}},
+		},
+	}
+}
+
+func subsetNames(eventBinding *linev1alpha1.EventBinding) []string {
+	names := []string{}
+	for _, subset := range eventBinding.Subsets {
+		names = append(names, subset.Binding.Name)
+	}
+	return names
+}
+
+// stalledClientset holds back the first list of the events, after it was read,
+// until another sync has written the eventbinding.
+type stalledClientset struct {
+	clientset.Interface
+	afterList func()
+}
+
+func (c *stalledClientset) LineV1alpha1() linev1alpha1client.LineV1alpha1Interface {
+	return &stalledLine{c.Interface.LineV1alpha1(), c.afterList}
+}
+
+type stalledLine struct {
+	linev1alpha1client.LineV1alpha1Interface
+	afterList func()
+}
+
+func (l *stalledLine) Events(namespace string) linev1alpha1client.EventInterface {
+	return &stalledEvents{l.LineV1alpha1Interface.Events(namespace), l.afterList}
+}
+
+type stalledEvents struct {
+	linev1alpha1client.EventInterface
+	afterList func()
+}
+
+func (e *stalledEvents) List(opts metav1.ListOptions) (*linev1alpha1.EventList, error) {
+	list, err := e.EventInterface.List(opts)
+	e.afterList()
+	return list, err
+}
+
+func TestSyncConcurrentEvents(t *testing.T) {
+	labels := map[string]string{"hunter": "monster"}
+	client := newFakeClientset(t, newEventBinding("test-bot", labels))
+
+	listed, release := make(chan struct{}), make(chan struct{})
+	stalled := int32(0)
+	c := newTestController(&stalledClientset{Interface: client, afterList: func() {
+		if atomic.CompareAndSwapInt32(&stalled, 0, 1) {
+			close(listed)
+			<-release
+		}
+	}})
+
+	apply := func(name string) error {
+		if _, err := client.LineV1alpha1().Events(testNamespace).Create(newEvent(name, labels)); err != nil {
+			return err
+		}
+		return c.sync(testNamespace + "/test-bot")
+	}
+
+	// The first sync computes its subsets without the second event, which is
+	// applied and synced before the first one writes.
+	done := make(chan error)
+	go func() {
+		done <- apply("hello")
+	}()
+	<-listed
+
+	if err := apply("bye"); err != nil {
+		t.Fatal(err)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	eventBinding, err := client.LineV1alpha1().EventBindings(testNamespace).Get("test-bot", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := fmt.Sprint(subsetNames(eventBinding)); names != "[bye hello]" {
+		t.Errorf("got subsets %s, want [bye hello]", names)
+	}
+}
@@ -0,0 +1,250 @@
+package eventbinding
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	linev1alpha1 "github.com/kairen/line-bot-operator/pkg/apis/line/v1alpha1"
+	linev1beta1 "github.com/kairen/line-bot-operator/pkg/apis/line/v1beta1"
+	"github.com/kairen/line-bot-operator/pkg/apis/line/validation"
+	"github.com/kairen/line-bot-operator/pkg/constants"
+	"github.com/kairen/line-bot-operator/pkg/k8sutil"
+	"github.com/kairen/line-bot-operator/pkg/rules"
+	"github.com/line/line-bot-sdk-go/linebot"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/klog"
+	"sigs.k8s.io/yaml"
+)
+
+// RulesName returns the name of the ConfigMap holding the rule snapshot of an
+// eventbinding, the bots mount it to swap their rules when it changes.
+func RulesName(name string) string {
+	return fmt.Sprintf("%s-rules", name)
+}
+
+// syncRules compiles the subsets of the eventbinding and the dialogs selecting it
+// into its rules ConfigMap. The eventbinding is read as v1beta1 to get the typed
+// replies.
+func (c *Controller) syncRules(namespace, name string) error {
+	eventBinding, err := c.clientset.LineV1beta1().EventBindings(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	dialogs, err := c.clientset.LineV1beta1().Dialogs(namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	// Invalid dialogs are reported by the dialog controller, they are left out so
+	// that they don't hold back the rules.
+	var selected []linev1beta1.Dialog
+	for _, dialog := range dialogs.Items {
+		if selects(dialog.Spec.Selector, eventBinding.Labels) &&
+			len(validation.ValidateDialogSpec(&dialog.Spec, field.NewPath("spec"))) == 0 {
+			selected = append(selected, dialog)
+		}
+	}
+
+	c.resolveSources(eventBinding)
+	snapshot, err := rules.Compile(eventBinding, selected)
+	if err != nil {
+		c.recorder.Eventf(eventBinding, v1.EventTypeWarning, constants.ReasonFailedCompile, "Failed to compile rules: %v", err)
+		return err
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      RulesName(name),
+			Namespace: namespace,
+			Labels:    map[string]string{"eventbinding": name},
+			Annotations: map[string]string{
+				constants.RulesVersionAnnotation:    snapshot.Version,
+				constants.RulesGenerationAnnotation: strconv.FormatInt(snapshot.Generation, 10),
+			},
+		},
+		Data: map[string]string{rules.SnapshotKey: string(data)},
+	}
+
+	ownerRef := metav1.NewControllerRef(eventBinding, schema.GroupVersionKind{
+		Group:   linev1alpha1.SchemeGroupVersion.Group,
+		Version: linev1alpha1.SchemeGroupVersion.Version,
+		Kind:    reflect.TypeOf(linev1alpha1.EventBinding{}).Name(),
+	})
+	k8sutil.SetOwnerRef(c.ctx.Clientset, namespace, &cm.ObjectMeta, ownerRef)
+
+	configMaps := c.ctx.Clientset.CoreV1().ConfigMaps(namespace)
+	current, err := configMaps.Get(cm.Name, metav1.GetOptions{})
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		if _, err := configMaps.Create(cm); err != nil {
+			return err
+		}
+	} else {
+		if reflect.DeepEqual(current.Data, cm.Data) {
+			return nil
+		}
+
+		current.Data = cm.Data
+		if current.Annotations == nil {
+			current.Annotations = map[string]string{}
+		}
+		for key, value := range cm.Annotations {
+			current.Annotations[key] = value
+		}
+		if _, err := configMaps.Update(current); err != nil {
+			return err
+		}
+	}
+
+	c.recorder.Eventf(eventBinding, v1.EventTypeNormal, constants.ReasonRulesCompiled, "Compiled %d rules and %d dialogs, version %s",
+		len(snapshot.Rules), len(snapshot.Dialogs), snapshot.Version)
+	klog.Infof("Success to compile rules on %s in %s namespace.", name, namespace)
+	return nil
+}
+
+// resolveSources merges the IDs of the source ConfigMaps into the allow and deny
+// lists of the subsets, and the dates of the holiday ConfigMaps into their
+// schedules. A subset whose ConfigMap can't be read or whose schedule is invalid
+// is left out of the rules, rather than replying in chats or at times it may not.
+func (c *Controller) resolveSources(eventBinding *linev1beta1.EventBinding) {
+	configMaps := c.ctx.Clientset.CoreV1().ConfigMaps(eventBinding.Namespace)
+	subsets := eventBinding.Subsets[:0]
+	for _, subset := range eventBinding.Subsets {
+		if source := subset.Source; source != nil && source.ConfigMapName != "" {
+			cm, err := configMaps.Get(source.ConfigMapName, metav1.GetOptions{})
+			if err != nil {
+				c.recorder.Eventf(eventBinding, v1.EventTypeWarning, constants.ReasonFailedCompile,
+					"Left out %s, failed to get source configmap %s: %v", subset.Name, source.ConfigMapName, err)
+				continue
+			}
+
+			resolved := source.DeepCopy()
+			resolved.Allow = append(resolved.Allow, splitIDs(cm.Data[constants.SourceAllowKey])...)
+			resolved.Deny = append(resolved.Deny, splitIDs(cm.Data[constants.SourceDenyKey])...)
+			resolved.ConfigMapName = ""
+			subset.Source = resolved
+		}
+
+		if schedule := subset.Schedule; schedule != nil && schedule.Holidays != nil && schedule.Holidays.ConfigMapName != "" {
+			cm, err := configMaps.Get(schedule.Holidays.ConfigMapName, metav1.GetOptions{})
+			if err != nil {
+				c.recorder.Eventf(eventBinding, v1.EventTypeWarning, constants.ReasonFailedCompile,
+					"Left out %s, failed to get holidays configmap %s: %v", subset.Name, schedule.Holidays.ConfigMapName, err)
+				continue
+			}
+
+			resolved := schedule.DeepCopy()
+			resolved.Holidays.Dates = append(resolved.Holidays.Dates, splitIDs(cm.Data[constants.HolidaysKey])...)
+			resolved.Holidays.ConfigMapName = ""
+			subset.Schedule = resolved
+		}
+
+		// The dates of a holidays ConfigMap are never checked by the webhook.
+		if subset.Schedule != nil {
+			if _, err := rules.NewSchedule(subset.Schedule); err != nil {
+				c.recorder.Eventf(eventBinding, v1.EventTypeWarning, constants.ReasonFailedCompile,
+					"Left out %s, invalid schedule: %v", subset.Name, err)
+				continue
+			}
+		}
+
+		messages, err := c.resolveI18n(eventBinding.Namespace, subset.Messages)
+		if err != nil {
+			c.recorder.Eventf(eventBinding, v1.EventTypeWarning, constants.ReasonFailedCompile, "Left out %s, %v", subset.Name, err)
+			continue
+		}
+		subset.Messages = messages
+		subsets = append(subsets, subset)
+	}
+	eventBinding.Subsets = subsets
+}
+
+// resolveI18n adds the replies of the translation bundles to the locales of the
+// messages, the inline replies win over the bundles, and checks their placeholders.
+func (c *Controller) resolveI18n(namespace string, messages []linev1beta1.Message) ([]linev1beta1.Message, error) {
+	resolved := make([]linev1beta1.Message, len(messages))
+	for i := range messages {
+		messages[i].DeepCopyInto(&resolved[i])
+		i18n := resolved[i].I18n
+		if i18n == nil {
+			continue
+		}
+
+		if i18n.ConfigMapName != "" {
+			cm, err := c.ctx.Clientset.CoreV1().ConfigMaps(namespace).Get(i18n.ConfigMapName, metav1.GetOptions{})
+			if err != nil {
+				return nil, fmt.Errorf("failed to get i18n configmap %s: %v", i18n.ConfigMapName, err)
+			}
+
+			for locale, data := range cm.Data {
+				bundle := map[string]string{}
+				if err := yaml.Unmarshal([]byte(data), &bundle); err != nil {
+					return nil, fmt.Errorf("invalid bundle %s of i18n configmap %s: %v", locale, i18n.ConfigMapName, err)
+				}
+
+				text, ok := bundle[i18n.Key]
+				if _, inline := i18n.Replies[locale]; !ok || inline {
+					continue
+				}
+				if i18n.Replies == nil {
+					i18n.Replies = map[string][]linev1beta1.ReplyMessage{}
+				}
+				i18n.Replies[locale] = []linev1beta1.ReplyMessage{{Type: linebot.MessageTypeText, Text: text}}
+			}
+			i18n.ConfigMapName = ""
+		}
+
+		texts := map[string]string{}
+		for locale, replies := range i18n.Replies {
+			texts[locale] = replyText(replies)
+		}
+		path := field.NewPath("messages").Index(i).Child("i18n", "replies")
+		if errs := validation.ValidatePlaceholders(replyText(resolved[i].Reply), texts, path); len(errs) > 0 {
+			return nil, errs.ToAggregate()
+		}
+		if _, ok := i18n.Replies[i18n.Fallback]; i18n.Fallback != "" && !ok {
+			return nil, fmt.Errorf("fallback locale %s of message %d has no reply", i18n.Fallback, i)
+		}
+	}
+	return resolved, nil
+}
+
+// replyText joins the texts of the replies, which hold the placeholders.
+func replyText(replies []linev1beta1.ReplyMessage) string {
+	texts := []string{}
+	for _, reply := range replies {
+		if reply.Type == linebot.MessageTypeText {
+			texts = append(texts, reply.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// splitIDs returns the IDs or dates of a ConfigMap value, one per line.
+func splitIDs(value string) []string {
+	ids := []string{}
+	for _, line := range strings.Split(value, "\n") {
+		if id := strings.TrimSpace(line); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
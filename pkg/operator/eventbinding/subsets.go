@@ -0,0 +1,202 @@
+package eventbinding
+
+import (
+	"reflect"
+	"sort"
+
+	linev1alpha1 "github.com/kairen/line-bot-operator/pkg/apis/line/v1alpha1"
+	"github.com/kairen/line-bot-operator/pkg/constants"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog"
+)
+
+// sync writes the subsets computed from the events and ClusterEvents selecting the
+// eventbinding, then compiles them into the rules. The events are listed again on
+// a conflict, so a retry never writes stale subsets.
+func (c *Controller) sync(key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+
+	eventBindings := c.clientset.LineV1alpha1().EventBindings(namespace)
+	var bound, unbound []runtime.Object
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		eventBinding, err := eventBindings.Get(name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		events, err := c.clientset.LineV1alpha1().Events(namespace).List(metav1.ListOptions{})
+		if err != nil {
+			return err
+		}
+
+		clusterEvents, err := c.clientset.LineV1alpha1().ClusterEvents().List(metav1.ListOptions{})
+		if err != nil {
+			return err
+		}
+
+		ns, err := c.ctx.Clientset.CoreV1().Namespaces().Get(namespace, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		subsets := computeSubsets(eventBinding, events.Items, clusterEvents.Items, ns.Labels)
+		if reflect.DeepEqual(eventBinding.Subsets, subsets) {
+			bound, unbound = nil, nil
+			return nil
+		}
+
+		bound, unbound = diffSubsets(eventBinding.Subsets, subsets, events.Items, clusterEvents.Items)
+		eventBinding.Subsets = subsets
+		_, err = eventBindings.Update(eventBinding)
+		return err
+	})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	for _, event := range bound {
+		c.recorder.Eventf(event, v1.EventTypeNormal, constants.ReasonBindingUpdated, "Bound to Bot %s in %s namespace", name, namespace)
+	}
+	for _, event := range unbound {
+		c.recorder.Eventf(event, v1.EventTypeNormal, constants.ReasonBindingUpdated, "Unbound from Bot %s in %s namespace", name, namespace)
+	}
+
+	if len(bound) > 0 || len(unbound) > 0 {
+		klog.Infof("Success to update subsets on %s in %s namespace.", name, namespace)
+	}
+	return c.syncRules(namespace, name)
+}
+
+// computeSubsets returns the subsets of the events and ClusterEvents selecting the
+// eventbinding, in the resolution order of the bindings.
+func computeSubsets(eventBinding *linev1alpha1.EventBinding, events []linev1alpha1.Event,
+	clusterEvents []linev1alpha1.ClusterEvent, namespaceLabels map[string]string) []linev1alpha1.EventBindingSubset {
+	var subsets []linev1alpha1.EventBindingSubset
+	for i := range events {
+		if selects(events[i].Spec.Selector, eventBinding.Labels) {
+			subsets = append(subsets, makeSubset(events[i].Name, linev1alpha1.EventOrigin, &events[i].Spec))
+		}
+	}
+
+	for i := range clusterEvents {
+		if selectsCluster(&clusterEvents[i], eventBinding, namespaceLabels) {
+			subsets = append(subsets, makeSubset(clusterEvents[i].Name, linev1alpha1.ClusterEventOrigin, &clusterEvents[i].Spec.EventSpec))
+		}
+	}
+
+	sort.Slice(subsets, func(i, j int) bool {
+		return linev1alpha1.BindingLess(&subsets[i].Binding, &subsets[j].Binding)
+	})
+	return subsets
+}
+
+func makeSubset(name string, origin linev1alpha1.BindingOrigin, spec *linev1alpha1.EventSpec) linev1alpha1.EventBindingSubset {
+	return linev1alpha1.EventBindingSubset{
+		Binding: linev1alpha1.Binding{
+			Name:     name,
+			Type:     spec.Type,
+			Messages: spec.Messages,
+			Priority: spec.Priority,
+			Origin:   origin,
+
+			Source:          spec.Source,
+			MentionRequired: spec.MentionRequired,
+			Schedule:        spec.Schedule,
+		},
+	}
+}
+
+// diffSubsets returns the events and ClusterEvents added to and removed from the
+// subsets, without the deleted ones.
+func diffSubsets(old, new []linev1alpha1.EventBindingSubset, events []linev1alpha1.Event,
+	clusterEvents []linev1alpha1.ClusterEvent) (bound, unbound []runtime.Object) {
+	oldRefs := map[string]bool{}
+	for _, subset := range old {
+		oldRefs[subset.Binding.Reference()] = true
+	}
+
+	newRefs := map[string]bool{}
+	for _, subset := range new {
+		newRefs[subset.Binding.Reference()] = true
+	}
+
+	objects := map[string]runtime.Object{}
+	for i := range events {
+		objects[events[i].Name] = &events[i]
+	}
+	for i := range clusterEvents {
+		binding := linev1alpha1.Binding{Name: clusterEvents[i].Name, Origin: linev1alpha1.ClusterEventOrigin}
+		objects[binding.Reference()] = &clusterEvents[i]
+	}
+
+	refs := make([]string, 0, len(objects))
+	for ref := range objects {
+		refs = append(refs, ref)
+	}
+	sort.Strings(refs)
+
+	for _, ref := range refs {
+		if newRefs[ref] && !oldRefs[ref] {
+			bound = append(bound, objects[ref])
+		} else if oldRefs[ref] && !newRefs[ref] {
+			unbound = append(unbound, objects[ref])
+		}
+	}
+	return bound, unbound
+}
+
+// selects reports whether the selector of an Event or a Dialog selects the labels
+// of an eventbinding, the empty selector selects nothing.
+func selects(labelSelector *metav1.LabelSelector, eventBindingLabels map[string]string) bool {
+	if labelSelector == nil {
+		return false
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(labelSelector)
+	if err != nil {
+		return false
+	}
+	return !selector.Empty() && selector.Matches(labels.Set(eventBindingLabels))
+}
+
+// selectsCluster reports whether a ClusterEvent selects the eventbinding. Unlike
+// the selector of an Event, an empty selector selects all the eventbindings, and
+// an empty namespace selector selects all the namespaces.
+func selectsCluster(event *linev1alpha1.ClusterEvent, eventBinding *linev1alpha1.EventBinding, namespaceLabels map[string]string) bool {
+	if !matches(event.Spec.Selector, eventBinding.Labels) {
+		return false
+	}
+	return event.Spec.NamespaceSelector == nil || matches(event.Spec.NamespaceSelector, namespaceLabels)
+}
+
+// matches reports whether the selector matches the labels, the nil selector
+// matches nothing and the empty one everything.
+func matches(labelSelector *metav1.LabelSelector, set map[string]string) bool {
+	if labelSelector == nil {
+		return false
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(labelSelector)
+	return err == nil && selector.Matches(labels.Set(set))
+}
+
+func hasSubset(eventBinding *linev1alpha1.EventBinding, origin linev1alpha1.BindingOrigin, name string) bool {
+	for _, subset := range eventBinding.Subsets {
+		if subset.Binding.Refers(origin, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,196 @@
+package eventbinding
+
+import (
+	"fmt"
+	"testing"
+
+	linev1alpha1 "github.com/kairen/line-bot-operator/pkg/apis/line/v1alpha1"
+	"github.com/line/line-bot-sdk-go/linebot"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var (
+	monster  = map[string]string{"hunter": "monster"}
+	terminal = map[string]string{"division": "terminal"}
+)
+
+func withType(event *linev1alpha1.Event, eventType linebot.EventType) *linev1alpha1.Event {
+	event.Spec.Type = eventType
+	return event
+}
+
+func subsetOf(event *linev1alpha1.Event) linev1alpha1.EventBindingSubset {
+	return makeSubset(event.Name, linev1alpha1.EventOrigin, &event.Spec)
+}
+
+func objectNames(objects []runtime.Object) []string {
+	names := []string{}
+	for _, obj := range objects {
+		names = append(names, obj.(metav1.Object).GetName())
+	}
+	return names
+}
+
+func TestComputeSubsets(t *testing.T) {
+	tests := []struct {
+		name   string
+		labels map[string]string
+		events []*linev1alpha1.Event
+		want   string
+	}{
+		{
+			name:   "selected events",
+			labels: monster,
+			events: []*linev1alpha1.Event{newEvent("hello", monster), newEvent("bye", monster)},
+			want:   "[bye/message hello/message]",
+		},
+		{
+			name:   "label moved",
+			labels: terminal,
+			events: []*linev1alpha1.Event{newEvent("hello", monster), newEvent("bye", terminal)},
+			want:   "[bye/message]",
+		},
+		{
+			name:   "type changed",
+			labels: monster,
+			events: []*linev1alpha1.Event{withType(newEvent("hello", monster), linebot.EventTypeFollow)},
+			want:   "[hello/follow]",
+		},
+		{
+			name:   "renamed",
+			labels: monster,
+			events: []*linev1alpha1.Event{newEvent("hi", monster)},
+			want:   "[hi/message]",
+		},
+		{
+			name:   "empty selector",
+			labels: monster,
+			events: []*linev1alpha1.Event{newEvent("hello", nil)},
+			want:   "[]",
+		},
+	}
+
+	for _, test := range tests {
+		events := []linev1alpha1.Event{}
+		for _, event := range test.events {
+			events = append(events, *event)
+		}
+
+		subsets := computeSubsets(newEventBinding("test-bot", test.labels), events, nil, nil)
+		got := []string{}
+		for _, subset := range subsets {
+			got = append(got, fmt.Sprintf("%s/%s", subset.Binding.Name, subset.Binding.Type))
+		}
+		if fmt.Sprint(got) != test.want {
+			t.Errorf("%s: got subsets %v, want %s", test.name, got, test.want)
+		}
+	}
+}
+
+func TestDiffSubsets(t *testing.T) {
+	hello := newEvent("hello", monster)
+
+	tests := []struct {
+		name        string
+		old         []*linev1alpha1.Event
+		events      []*linev1alpha1.Event
+		labels      map[string]string
+		wantBound   string
+		wantUnbound string
+	}{
+		{
+			name:        "bound",
+			events:      []*linev1alpha1.Event{hello},
+			labels:      monster,
+			wantBound:   "[hello]",
+			wantUnbound: "[]",
+		},
+		{
+			name:        "label moved",
+			old:         []*linev1alpha1.Event{hello},
+			events:      []*linev1alpha1.Event{newEvent("hello", terminal)},
+			labels:      monster,
+			wantBound:   "[]",
+			wantUnbound: "[hello]",
+		},
+		{
+			name:        "type changed",
+			old:         []*linev1alpha1.Event{hello},
+			events:      []*linev1alpha1.Event{withType(newEvent("hello", monster), linebot.EventTypeFollow)},
+			labels:      monster,
+			wantBound:   "[]",
+			wantUnbound: "[]",
+		},
+		{
+			// The old event is deleted, so only the new one gets a Kubernetes event.
+			name:        "renamed",
+			old:         []*linev1alpha1.Event{hello},
+			events:      []*linev1alpha1.Event{newEvent("hi", monster)},
+			labels:      monster,
+			wantBound:   "[hi]",
+			wantUnbound: "[]",
+		},
+	}
+
+	for _, test := range tests {
+		old := []linev1alpha1.EventBindingSubset{}
+		for _, event := range test.old {
+			old = append(old, subsetOf(event))
+		}
+		events := []linev1alpha1.Event{}
+		for _, event := range test.events {
+			events = append(events, *event)
+		}
+
+		new := computeSubsets(newEventBinding("test-bot", test.labels), events, nil, nil)
+		bound, unbound := diffSubsets(old, new, events, nil)
+		if got := fmt.Sprint(objectNames(bound)); got != test.wantBound {
+			t.Errorf("%s: got bound %s, want %s", test.name, got, test.wantBound)
+		}
+		if got := fmt.Sprint(objectNames(unbound)); got != test.wantUnbound {
+			t.Errorf("%s: got unbound %s, want %s", test.name, got, test.wantUnbound)
+		}
+	}
+}
+
+// TestEventSelectorMove moves an event from the monster hunters to the terminal
+// division, the eventbinding selected by the old selector must drop its subset.
+func TestEventSelectorMove(t *testing.T) {
+	old := newEvent("hello", monster)
+	new := newEvent("hello", terminal)
+
+	mhw := newEventBinding("mhw", monster)
+	mhw.Subsets = []linev1alpha1.EventBindingSubset{subsetOf(old)}
+	term := newEventBinding("terminal", terminal)
+
+	// The cache doesn't show the subset yet, so only the old selector finds mhw.
+	client := newFakeClientset(t, mhw, term, new)
+	c := newTestController(client, newEventBinding("mhw", monster), term)
+
+	c.onEventUpdate(old, new)
+	keys := map[string]bool{}
+	for c.queue.Len() > 0 {
+		key, _ := c.queue.Get()
+		keys[key.(string)] = true
+		c.queue.Done(key)
+	}
+	if !keys[testNamespace+"/mhw"] || !keys[testNamespace+"/terminal"] {
+		t.Fatalf("got queued eventbindings %v, want mhw and terminal", keys)
+	}
+
+	want := map[string]string{"mhw": "[]", "terminal": "[hello]"}
+	for name, subsets := range want {
+		if err := c.sync(testNamespace + "/" + name); err != nil {
+			t.Fatal(err)
+		}
+
+		eventBinding, err := client.LineV1alpha1().EventBindings(testNamespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := fmt.Sprint(subsetNames(eventBinding)); got != subsets {
+			t.Errorf("%s: got subsets %s, want %s", name, got, subsets)
+		}
+	}
+}
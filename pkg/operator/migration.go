@@ -0,0 +1,100 @@
+package operator
+
+import (
+	"fmt"
+
+	linev1beta1 "github.com/kairen/line-bot-operator/pkg/apis/line/v1beta1"
+	opkit "github.com/kubedev/operator-kit"
+	v1 "k8s.io/api/core/v1"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog"
+)
+
+// migrateStorageVersion rewrites every object of the resource once its CRD stores
+// v1beta1, so that no object is persisted in an older version anymore.
+func (o *Operator) migrateStorageVersion(resource opkit.CustomResource) error {
+	name := fmt.Sprintf("%s.%s", resource.Plural, resource.Group)
+	crds := o.ctx.APIExtensionClientset.ApiextensionsV1beta1().CustomResourceDefinitions()
+	crd, err := crds.Get(name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	storage := storageVersion(crd)
+	if storage != linev1beta1.Version {
+		return nil
+	}
+
+	if len(crd.Status.StoredVersions) == 1 && crd.Status.StoredVersions[0] == storage {
+		return nil
+	}
+
+	klog.Infof("Migrating %s from %v to the %s storage version.", name, crd.Status.StoredVersions, storage)
+	if err := o.rewriteObjects(resource.Kind); err != nil {
+		return err
+	}
+
+	crd.Status.StoredVersions = []string{storage}
+	if _, err := crds.UpdateStatus(crd); err != nil {
+		return err
+	}
+	klog.Infof("Success to migrate %s to the %s storage version.", name, storage)
+	return nil
+}
+
+func (o *Operator) rewriteObjects(kind string) error {
+	client := o.lineClient.LineV1beta1()
+	switch kind {
+	case "Bot":
+		bots, err := client.Bots(v1.NamespaceAll).List(metav1.ListOptions{})
+		if err != nil {
+			return err
+		}
+		for _, bot := range bots.Items {
+			if _, err := client.Bots(bot.Namespace).Update(&bot); ignoreGone(err) != nil {
+				return err
+			}
+		}
+	case "Event":
+		events, err := client.Events(v1.NamespaceAll).List(metav1.ListOptions{})
+		if err != nil {
+			return err
+		}
+		for _, event := range events.Items {
+			if _, err := client.Events(event.Namespace).Update(&event); ignoreGone(err) != nil {
+				return err
+			}
+		}
+	case "EventBinding":
+		eventBindings, err := client.EventBindings(v1.NamespaceAll).List(metav1.ListOptions{})
+		if err != nil {
+			return err
+		}
+		for _, eventBinding := range eventBindings.Items {
+			if _, err := client.EventBindings(eventBinding.Namespace).Update(&eventBinding); ignoreGone(err) != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func storageVersion(crd *apiextensionsv1beta1.CustomResourceDefinition) string {
+	for _, version := range crd.Spec.Versions {
+		if version.Storage {
+			return version.Name
+		}
+	}
+	return crd.Spec.Version
+}
+
+// ignoreGone ignores the objects deleted or changed since they were listed, a
+// concurrent write has stored them in the storage version already.
+func ignoreGone(err error) error {
+	if errors.IsNotFound(err) || errors.IsConflict(err) {
+		return nil
+	}
+	return err
+}
@@ -7,52 +7,96 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/kairen/line-bot-operator/pkg/config"
 	clientset "github.com/kairen/line-bot-operator/pkg/generated/clientset/versioned"
 	"github.com/kairen/line-bot-operator/pkg/k8sutil"
+	"github.com/kairen/line-bot-operator/pkg/metrics"
 	"github.com/kairen/line-bot-operator/pkg/operator/bot"
+	"github.com/kairen/line-bot-operator/pkg/operator/clusterevent"
+	"github.com/kairen/line-bot-operator/pkg/operator/dialog"
 	"github.com/kairen/line-bot-operator/pkg/operator/event"
 	"github.com/kairen/line-bot-operator/pkg/operator/eventbinding"
+	"github.com/kairen/line-bot-operator/pkg/webhook"
 	opkit "github.com/kubedev/operator-kit"
 	v1 "k8s.io/api/core/v1"
-
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
 	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/klog"
 )
 
 const (
+	component      = "line-bot-operator"
 	initRetryDelay = 10 * time.Second
 	interval       = 500 * time.Millisecond
 	timeout        = 60 * time.Second
 )
 
+type Flags struct {
+	Kubeconfig         string
+	ConfigFile         string
+	WebhookBindAddress string
+	TLSCertFile        string
+	TLSPrivateKeyFile  string
+	MetricsBindAddress string
+}
+
 type Operator struct {
 	ctx               *opkit.Context
+	lineClient        clientset.Interface
+	config            *config.Store
+	flags             *Flags
 	resources         []opkit.CustomResource
 	botController     *bot.Controller
 	eventController   *event.Controller
 	bindingController *eventbinding.Controller
+	clusterController *clusterevent.Controller
+	dialogController  *dialog.Controller
+	webhookServer     *webhook.Server
 }
 
-func NewMainOperator() *Operator {
-	return &Operator{resources: []opkit.CustomResource{
-		bot.Resource,
-		event.Resource,
-		eventbinding.Resource,
-	}}
+func NewMainOperator(flags *Flags) *Operator {
+	return &Operator{
+		flags: flags,
+		resources: []opkit.CustomResource{
+			bot.Resource,
+			event.Resource,
+			eventbinding.Resource,
+			clusterevent.Resource,
+			dialog.Resource,
+		},
+	}
 }
 
-func (o *Operator) Initialize(kubeconfig string) error {
+func (o *Operator) Initialize() error {
 	klog.V(2).Info("Initialize the operator resources.")
-	ctx, lineClient, err := o.initContextAndClient(kubeconfig)
+	ctx, lineClient, err := o.initContextAndClient(o.flags.Kubeconfig)
 	if err != nil {
 		return err
 	}
 
-	o.botController = bot.NewController(ctx, lineClient)
-	o.eventController = event.NewController(ctx, lineClient)
-	o.bindingController = eventbinding.NewController(ctx, lineClient)
+	store, err := config.NewStore(o.flags.ConfigFile)
+	if err != nil {
+		return fmt.Errorf("Failed to load operator config. %+v", err)
+	}
+
+	recorder := k8sutil.NewEventRecorder(ctx.Clientset, component)
+	o.botController = bot.NewController(ctx, lineClient, recorder, store)
+	o.eventController = event.NewController(ctx, lineClient, recorder)
+	o.bindingController = eventbinding.NewController(ctx, lineClient, recorder)
+	o.clusterController = clusterevent.NewController(ctx, lineClient, recorder)
+	o.dialogController = dialog.NewController(ctx, lineClient, recorder)
+
+	// The webhook is optional, it is only served when the TLS key pair is given.
+	if o.flags.TLSCertFile != "" && o.flags.TLSPrivateKeyFile != "" {
+		o.webhookServer = webhook.NewServer(ctx, lineClient, store,
+			o.flags.WebhookBindAddress, o.flags.TLSCertFile, o.flags.TLSPrivateKeyFile)
+	}
 	o.ctx = ctx
+	o.lineClient = lineClient
+	o.config = store
 	return nil
 }
 
@@ -88,20 +132,36 @@ func (o *Operator) initContextAndClient(kubeconfig string) (*opkit.Context, clie
 	return ctx, lineClient, nil
 }
 
+// initResources checks that the CRDs of deploy/crd.yml are established. They are
+// not created by the operator, because they serve several versions through the
+// conversion webhook and a status subresource.
 func (o *Operator) initResources() error {
-	klog.V(2).Info("Initialize the CRD resources.")
+	klog.V(2).Info("Check the CRD resources.")
+
+	crds := o.ctx.APIExtensionClientset.ApiextensionsV1beta1().CustomResourceDefinitions()
+	for _, resource := range o.resources {
+		name := fmt.Sprintf("%s.%s", resource.Plural, resource.Group)
+		crd, err := crds.Get(name, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			return fmt.Errorf("CRD %s is not installed, apply deploy/crd.yml", name)
+		} else if err != nil {
+			return err
+		}
 
-	ctx := opkit.Context{
-		Clientset:             o.ctx.Clientset,
-		APIExtensionClientset: o.ctx.APIExtensionClientset,
-		Interval:              interval,
-		Timeout:               timeout,
+		if !isEstablished(crd) {
+			return fmt.Errorf("CRD %s is not established yet", name)
+		}
 	}
+	return nil
+}
 
-	if err := opkit.CreateCustomResources(ctx, o.resources); err != nil {
-		return fmt.Errorf("Failed to create custom resource. %+v", err)
+func isEstablished(crd *apiextensionsv1beta1.CustomResourceDefinition) bool {
+	for _, condition := range crd.Status.Conditions {
+		if condition.Type == apiextensionsv1beta1.Established {
+			return condition.Status == apiextensionsv1beta1.ConditionTrue
+		}
 	}
-	return nil
+	return false
 }
 
 func (o *Operator) Run() error {
@@ -114,15 +174,33 @@ func (o *Operator) Run() error {
 		<-time.After(initRetryDelay)
 	}
 
+	for _, resource := range o.resources {
+		if err := o.migrateStorageVersion(resource); err != nil {
+			klog.Errorf("Failed to migrate the storage version of %s. %+v.", resource.Plural, err)
+		}
+	}
+
 	signalChan := make(chan os.Signal, 1)
 	stopChan := make(chan struct{})
 	signal.Notify(signalChan, syscall.SIGINT, syscall.SIGTERM)
 
+	o.config.Run(stopChan)
+
 	// start watching the resources
 	o.bindingController.StartWatch(v1.NamespaceAll, stopChan)
 	o.eventController.StartWatch(v1.NamespaceAll, stopChan)
+	o.clusterController.StartWatch(stopChan)
+	o.dialogController.StartWatch(v1.NamespaceAll, stopChan)
 	o.botController.StartWatch(v1.NamespaceAll, stopChan)
 
+	if o.webhookServer != nil {
+		o.webhookServer.Run(stopChan)
+	}
+
+	if o.flags.MetricsBindAddress != "" {
+		metrics.Serve(o.flags.MetricsBindAddress, stopChan)
+	}
+
 	for {
 		select {
 		case <-signalChan:
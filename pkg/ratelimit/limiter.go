@@ -0,0 +1,232 @@
+package ratelimit
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	linev1beta1 "github.com/kairen/line-bot-operator/pkg/apis/line/v1beta1"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Decision tells a bot what to do with a reply.
+type Decision int
+
+const (
+	// Allow sends the reply.
+	Allow Decision = iota
+	// Drop doesn't reply.
+	Drop
+	// Notice replies with the notice of the rate limit instead.
+	Notice
+)
+
+// Limits hit by the replies, they label the metrics.
+const (
+	SourceLimit   = "source"
+	GlobalLimit   = "global"
+	CooldownLimit = "cooldown"
+)
+
+var (
+	allowedTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "linebot_replies_allowed_total",
+		Help: "Replies allowed by the rate limit.",
+	})
+	limitedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "linebot_replies_limited_total",
+		Help: "Replies over the rate limit, by limit and action.",
+	}, []string{"limit", "action"})
+)
+
+// Register registers the reply metrics, it is called by the bot runtime and not
+// on import, so that the operator doesn't export them.
+func Register(registerer prometheus.Registerer) error {
+	for _, collector := range []prometheus.Collector{allowedTotal, limitedTotal} {
+		if err := registerer.Register(collector); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pruneInterval is how often the idle buckets and expired cooldowns are removed.
+const pruneInterval = time.Minute
+
+// Limiter enforces the rate limit of a bot, it is safe for concurrent use.
+type Limiter struct {
+	spec linev1beta1.BotRateLimit
+
+	mu        sync.Mutex
+	global    *bucket
+	sources   map[string]*bucket
+	cooldowns map[string]time.Time
+	// noticed are the sources sent the notice since they were last allowed.
+	noticed   map[string]bool
+	lastPrune time.Time
+}
+
+// New returns the limiter of a spec, nil allows all the replies.
+func New(spec *linev1beta1.BotRateLimit) *Limiter {
+	l := &Limiter{
+		sources:   map[string]*bucket{},
+		cooldowns: map[string]time.Time{},
+		noticed:   map[string]bool{},
+	}
+	if spec == nil {
+		return l
+	}
+
+	l.spec = *spec.DeepCopy()
+	if spec.RepliesPerSecond > 0 {
+		burst := spec.Burst
+		if burst == 0 {
+			burst = spec.RepliesPerSecond
+		}
+		l.global = newBucket(burst, time.Second/time.Duration(spec.RepliesPerSecond), time.Time{})
+	}
+	return l
+}
+
+// Load parses the JSON spec passed to a bot, e.g. in the RATE_LIMIT environment
+// variable, an empty spec allows all the replies.
+func Load(data []byte) (*Limiter, error) {
+	if len(data) == 0 {
+		return New(nil), nil
+	}
+
+	spec := &linev1beta1.BotRateLimit{}
+	if err := json.Unmarshal(data, spec); err != nil {
+		return nil, err
+	}
+	return New(spec), nil
+}
+
+// Allow decides whether a rule replies to a source, i.e. a user, group or room
+// ID. The rule cooldown is checked first, then the bucket of the source and the
+// global limit.
+func (l *Limiter) Allow(source, rule string, now time.Time) Decision {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.prune(now)
+
+	cooldownKey := rule + "\x00" + source
+	if until, ok := l.cooldowns[cooldownKey]; ok && now.Before(until) {
+		return l.limited(CooldownLimit, source, now)
+	}
+
+	var sourceBucket *bucket
+	if perSource := l.spec.PerSource; perSource != nil {
+		sourceBucket = l.sources[source]
+		if sourceBucket == nil {
+			sourceBucket = newBucket(perSource.Capacity, perSource.RefillInterval.Duration, now)
+			l.sources[source] = sourceBucket
+		}
+		if !sourceBucket.take(now) {
+			return l.limited(SourceLimit, source, now)
+		}
+	}
+
+	if l.global != nil && !l.global.take(now) {
+		// The chat didn't use its token.
+		if sourceBucket != nil {
+			sourceBucket.tokens++
+		}
+		limitedTotal.WithLabelValues(GlobalLimit, "drop").Inc()
+		return Drop
+	}
+
+	if cooldown := l.spec.RuleCooldown; cooldown != nil {
+		l.cooldowns[cooldownKey] = now.Add(cooldown.Duration)
+	}
+	delete(l.noticed, source)
+	allowedTotal.Inc()
+	return Allow
+}
+
+// limited sends the notice once to a source over a limit, when it is the action
+// and the global limit allows it.
+func (l *Limiter) limited(limit, source string, now time.Time) Decision {
+	if l.spec.Action != linev1beta1.RateLimitNotice || l.noticed[source] {
+		limitedTotal.WithLabelValues(limit, "drop").Inc()
+		return Drop
+	}
+
+	if l.global != nil && !l.global.take(now) {
+		limitedTotal.WithLabelValues(limit, "drop").Inc()
+		return Drop
+	}
+
+	l.noticed[source] = true
+	limitedTotal.WithLabelValues(limit, "notice").Inc()
+	return Notice
+}
+
+// NoticeText is the text of the Notice decision.
+func (l *Limiter) NoticeText() string {
+	return l.spec.Notice
+}
+
+func (l *Limiter) prune(now time.Time) {
+	if now.Sub(l.lastPrune) < pruneInterval {
+		return
+	}
+	l.lastPrune = now
+
+	// A source with a full bucket is allowed again, and one without a bucket is
+	// only noticed again once per prune.
+	for source, b := range l.sources {
+		if b.full(now) {
+			delete(l.sources, source)
+		}
+	}
+	for source := range l.noticed {
+		if l.sources[source] == nil {
+			delete(l.noticed, source)
+		}
+	}
+	for key, until := range l.cooldowns {
+		if !now.Before(until) {
+			delete(l.cooldowns, key)
+		}
+	}
+}
+
+// bucket holds up to capacity tokens, and gets one back every interval.
+type bucket struct {
+	capacity float64
+	interval time.Duration
+	tokens   float64
+	last     time.Time
+}
+
+func newBucket(capacity int32, interval time.Duration, now time.Time) *bucket {
+	return &bucket{capacity: float64(capacity), interval: interval, tokens: float64(capacity), last: now}
+}
+
+func (b *bucket) refill(now time.Time) {
+	if b.last.IsZero() {
+		b.last = now
+	}
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens += float64(elapsed) / float64(b.interval)
+		if b.tokens > b.capacity {
+			b.tokens = b.capacity
+		}
+		b.last = now
+	}
+}
+
+func (b *bucket) take(now time.Time) bool {
+	b.refill(now)
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+func (b *bucket) full(now time.Time) bool {
+	b.refill(now)
+	return b.tokens >= b.capacity
+}
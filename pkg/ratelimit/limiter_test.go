@@ -0,0 +1,239 @@
+package ratelimit
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	linev1beta1 "github.com/kairen/line-bot-operator/pkg/apis/line/v1beta1"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type step struct {
+	source string
+	rule   string
+	at     time.Duration
+	want   Decision
+}
+
+func perSource(capacity int32, interval time.Duration) *linev1beta1.TokenBucket {
+	return &linev1beta1.TokenBucket{Capacity: capacity, RefillInterval: metav1.Duration{Duration: interval}}
+}
+
+func TestAllow(t *testing.T) {
+	tests := []struct {
+		name  string
+		spec  *linev1beta1.BotRateLimit
+		steps []step
+	}{
+		{
+			name: "no limit",
+			spec: nil,
+			steps: []step{
+				{"alice", "hello", 0, Allow},
+				{"alice", "hello", 0, Allow},
+				{"alice", "hello", 0, Allow},
+			},
+		},
+		{
+			name: "source bucket",
+			spec: &linev1beta1.BotRateLimit{PerSource: perSource(2, 10*time.Second)},
+			steps: []step{
+				{"alice", "hello", 0, Allow},
+				{"alice", "hello", 0, Allow},
+				{"alice", "hello", time.Second, Drop},
+				{"bob", "hello", time.Second, Allow},
+				{"alice", "hello", 10 * time.Second, Allow},
+				{"alice", "hello", 10 * time.Second, Drop},
+			},
+		},
+		{
+			name: "global limit",
+			spec: &linev1beta1.BotRateLimit{RepliesPerSecond: 2},
+			steps: []step{
+				{"alice", "hello", 0, Allow},
+				{"bob", "hello", 0, Allow},
+				{"carol", "hello", 0, Drop},
+				{"carol", "hello", 500 * time.Millisecond, Allow},
+			},
+		},
+		{
+			name: "global burst",
+			spec: &linev1beta1.BotRateLimit{RepliesPerSecond: 1, Burst: 3},
+			steps: []step{
+				{"alice", "hello", 0, Allow},
+				{"bob", "hello", 0, Allow},
+				{"carol", "hello", 0, Allow},
+				{"dave", "hello", 0, Drop},
+			},
+		},
+		{
+			// The source gets its token back when the global limit drops the reply.
+			name: "global limit refunds the source",
+			spec: &linev1beta1.BotRateLimit{PerSource: perSource(1, time.Minute), RepliesPerSecond: 1},
+			steps: []step{
+				{"alice", "hello", 0, Allow},
+				{"bob", "hello", 0, Drop},
+				{"bob", "hello", time.Second, Allow},
+			},
+		},
+		{
+			name: "rule cooldown",
+			spec: &linev1beta1.BotRateLimit{RuleCooldown: &metav1.Duration{Duration: 30 * time.Second}},
+			steps: []step{
+				{"alice", "hello", 0, Allow},
+				{"alice", "hello", 10 * time.Second, Drop},
+				{"alice", "bye", 10 * time.Second, Allow},
+				{"bob", "hello", 10 * time.Second, Allow},
+				{"alice", "hello", 30 * time.Second, Allow},
+			},
+		},
+		{
+			name: "notice",
+			spec: &linev1beta1.BotRateLimit{
+				PerSource: perSource(1, time.Minute),
+				Action:    linev1beta1.RateLimitNotice,
+				Notice:    "Slow down",
+			},
+			steps: []step{
+				{"alice", "hello", 0, Allow},
+				{"alice", "hello", 0, Notice},
+				{"alice", "hello", time.Second, Drop},
+				{"alice", "hello", time.Minute, Allow},
+				{"alice", "hello", time.Minute, Notice},
+			},
+		},
+		{
+			name: "notice on cooldown",
+			spec: &linev1beta1.BotRateLimit{
+				RuleCooldown: &metav1.Duration{Duration: time.Minute},
+				Action:       linev1beta1.RateLimitNotice,
+				Notice:       "Slow down",
+			},
+			steps: []step{
+				{"alice", "hello", 0, Allow},
+				{"alice", "hello", time.Second, Notice},
+				{"alice", "hello", 2 * time.Second, Drop},
+			},
+		},
+		{
+			name: "notice over the global limit",
+			spec: &linev1beta1.BotRateLimit{
+				PerSource:        perSource(1, time.Minute),
+				RepliesPerSecond: 1,
+				Action:           linev1beta1.RateLimitNotice,
+				Notice:           "Slow down",
+			},
+			steps: []step{
+				{"alice", "hello", 0, Allow},
+				{"alice", "hello", 0, Drop},
+				{"alice", "hello", time.Second, Notice},
+			},
+		},
+	}
+
+	start := time.Date(2019, 4, 1, 12, 0, 0, 0, time.UTC)
+	for _, test := range tests {
+		l := New(test.spec)
+		for i, s := range test.steps {
+			if got := l.Allow(s.source, s.rule, start.Add(s.at)); got != s.want {
+				t.Errorf("%s: step %d: got %v for %s at %v, want %v", test.name, i, got, s.source, s.at, s.want)
+			}
+		}
+	}
+}
+
+func TestPrune(t *testing.T) {
+	l := New(&linev1beta1.BotRateLimit{
+		PerSource:    perSource(1, 10*time.Second),
+		RuleCooldown: &metav1.Duration{Duration: 2 * pruneInterval},
+		Action:       linev1beta1.RateLimitNotice,
+	})
+
+	start := time.Date(2019, 4, 1, 12, 0, 0, 0, time.UTC)
+	l.Allow("alice", "hello", start)
+	l.Allow("bob", "hello", start)
+	if got := l.Allow("bob", "bye", start); got != Notice {
+		t.Fatalf("got %v for bob, want %v", got, Notice)
+	}
+
+	// The buckets are full again, the cooldowns are still running.
+	l.Allow("carol", "hello", start.Add(pruneInterval))
+	if len(l.sources) != 1 || l.sources["carol"] == nil {
+		t.Errorf("got %d source buckets, want the one of carol", len(l.sources))
+	}
+	if len(l.noticed) != 0 {
+		t.Errorf("got noticed sources %v, want none", l.noticed)
+	}
+	if len(l.cooldowns) != 3 {
+		t.Errorf("got %d cooldowns, want 3", len(l.cooldowns))
+	}
+
+	l.Allow("carol", "bye", start.Add(3*pruneInterval))
+	if len(l.cooldowns) != 1 {
+		t.Errorf("got %d cooldowns, want the one of carol", len(l.cooldowns))
+	}
+}
+
+func TestLoad(t *testing.T) {
+	l, err := Load([]byte(`{"perSource":{"capacity":1,"refillInterval":"1m"},"action":"Notice","notice":"Slow down"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.NoticeText() != "Slow down" {
+		t.Errorf("got notice %q, want %q", l.NoticeText(), "Slow down")
+	}
+
+	now := time.Now()
+	if l.Allow("alice", "hello", now) != Allow || l.Allow("alice", "hello", now) != Notice {
+		t.Error("got no notice over the source bucket")
+	}
+
+	if l, err := Load(nil); err != nil || l.Allow("alice", "hello", now) != Allow {
+		t.Errorf("got %v for an empty spec, want a limiter allowing the replies", err)
+	}
+	if _, err := Load([]byte("{")); err == nil {
+		t.Error("got no error for an invalid spec")
+	}
+}
+
+func TestRegister(t *testing.T) {
+	// The operator imports the package, but doesn't export the reply metrics.
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, family := range families {
+		if strings.HasPrefix(family.GetName(), "linebot_replies_") {
+			t.Errorf("got %s in the default registry", family.GetName())
+		}
+	}
+
+	registry := prometheus.NewRegistry()
+	if err := Register(registry); err != nil {
+		t.Fatal(err)
+	}
+
+	allowed := testutil.ToFloat64(allowedTotal)
+	dropped := testutil.ToFloat64(limitedTotal.WithLabelValues(SourceLimit, "drop"))
+	l := New(&linev1beta1.BotRateLimit{PerSource: perSource(1, time.Minute)})
+	l.Allow("alice", "hello", time.Now())
+	l.Allow("alice", "hello", time.Now())
+
+	if got := testutil.ToFloat64(allowedTotal) - allowed; got != 1 {
+		t.Errorf("got %v allowed replies, want 1", got)
+	}
+	if got := testutil.ToFloat64(limitedTotal.WithLabelValues(SourceLimit, "drop")) - dropped; got != 1 {
+		t.Errorf("got %v dropped replies, want 1", got)
+	}
+
+	families, err = registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(families) != 2 {
+		t.Errorf("got %d metric families, want 2", len(families))
+	}
+}
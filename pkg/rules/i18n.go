@@ -0,0 +1,46 @@
+package rules
+
+import (
+	"sort"
+	"strings"
+
+	linev1beta1 "github.com/kairen/line-bot-operator/pkg/apis/line/v1beta1"
+)
+
+// Localize returns the reply of the rule for a language of a user profile, e.g.
+// zh-TW. The locale equal to the language wins, then a locale of the same base
+// language, then the fallback locale and the reply of the rule.
+func (r *Rule) Localize(language string) []linev1beta1.ReplyMessage {
+	i18n := r.I18n
+	if i18n == nil {
+		return r.Reply
+	}
+
+	locales := make([]string, 0, len(i18n.Replies))
+	for locale := range i18n.Replies {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+
+	if language != "" {
+		for _, locale := range locales {
+			if strings.EqualFold(locale, language) {
+				return i18n.Replies[locale]
+			}
+		}
+		for _, locale := range locales {
+			if strings.EqualFold(baseLanguage(locale), baseLanguage(language)) {
+				return i18n.Replies[locale]
+			}
+		}
+	}
+
+	if reply, ok := i18n.Replies[i18n.Fallback]; ok && i18n.Fallback != "" {
+		return reply
+	}
+	return r.Reply
+}
+
+func baseLanguage(locale string) string {
+	return strings.SplitN(locale, "-", 2)[0]
+}
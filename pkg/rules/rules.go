@@ -0,0 +1,202 @@
+package rules
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+
+	linev1beta1 "github.com/kairen/line-bot-operator/pkg/apis/line/v1beta1"
+	"github.com/kairen/line-bot-operator/pkg/conversation"
+	"github.com/line/line-bot-sdk-go/linebot"
+)
+
+// SnapshotKey is the key of the snapshot in the rules ConfigMap of an eventbinding.
+const SnapshotKey = "rules.json"
+
+// Snapshot is the rule table compiled from the subsets of an EventBinding. The
+// rules are in the resolution order of the subsets, so the first matching rule
+// wins, and every index refers to the rules.
+type Snapshot struct {
+	// Version is the content hash of the rules, bots report it with each reply.
+	Version string `json:"version"`
+	// Generation is the generation of the EventBinding the rules are compiled from.
+	Generation int64 `json:"generation"`
+
+	Rules []Rule `json:"rules"`
+	// Buckets are the rules of each webhook event type.
+	Buckets map[linebot.EventType][]int `json:"buckets"`
+	// Keywords is a trie of the keywords of the message rules.
+	Keywords *TrieNode `json:"keywords,omitempty"`
+	Patterns []Pattern `json:"patterns,omitempty"`
+	// Dialogs are the conversation flows selecting the EventBinding, by name.
+	Dialogs []Dialog `json:"dialogs,omitempty"`
+}
+
+type Dialog struct {
+	Name string                 `json:"name"`
+	Spec linev1beta1.DialogSpec `json:"spec"`
+}
+
+// Rule is a message of a bound event.
+type Rule struct {
+	// ID is the event name and the index of the message, e.g. hello-event/0, the
+	// name of a ClusterEvent is prefixed by ClusterEvent/.
+	ID          string                     `json:"id"`
+	Event       string                     `json:"event"`
+	Origin      linev1beta1.BindingOrigin  `json:"origin,omitempty"`
+	Priority    int32                      `json:"priority,omitempty"`
+	MessageType linebot.MessageType        `json:"messageType,omitempty"`
+	Reply       []linev1beta1.ReplyMessage `json:"reply,omitempty"`
+	// Variants replace the reply when set, see Picker.
+	Variants  []linev1beta1.ReplyVariant  `json:"variants,omitempty"`
+	Selection *linev1beta1.ReplySelection `json:"selection,omitempty"`
+	// I18n has the replies of its bundles merged into the locales, see Localize.
+	I18n *linev1beta1.MessageI18n `json:"i18n,omitempty"`
+	// CatchAll rules match any message, they have no keywords or patterns.
+	CatchAll bool `json:"catchAll,omitempty"`
+	// Source has the IDs of its ConfigMap merged into the allow and deny lists.
+	Source          *linev1beta1.EventSource `json:"source,omitempty"`
+	MentionRequired bool                     `json:"mentionRequired,omitempty"`
+	// Schedule has the dates of its ConfigMap merged into the holidays.
+	Schedule *linev1beta1.EventSchedule `json:"schedule,omitempty"`
+}
+
+// TrieNode is a node of the keyword trie, a keyword ending at the node matches
+// its rules.
+type TrieNode struct {
+	Children map[string]*TrieNode `json:"children,omitempty"`
+	Rules    []int                `json:"rules,omitempty"`
+}
+
+type Pattern struct {
+	Expr string `json:"expr"`
+	Rule int    `json:"rule"`
+}
+
+// Compile builds the snapshot of an eventbinding, whose subsets are expected to
+// be in resolution order with the source ConfigMaps resolved, and of the dialogs
+// selecting it.
+func Compile(eventBinding *linev1beta1.EventBinding, dialogs []linev1beta1.Dialog) (*Snapshot, error) {
+	snapshot := &Snapshot{
+		Generation: eventBinding.Generation,
+		Rules:      []Rule{},
+		Buckets:    map[linebot.EventType][]int{},
+	}
+
+	for _, subset := range eventBinding.Subsets {
+		ref := subset.Name
+		if subset.Origin == linev1beta1.ClusterEventOrigin {
+			ref = fmt.Sprintf("%s/%s", subset.Origin, subset.Name)
+		}
+
+		if subset.Schedule != nil {
+			if _, err := NewSchedule(subset.Schedule); err != nil {
+				return nil, fmt.Errorf("invalid schedule of %s: %v", ref, err)
+			}
+		}
+
+		for i, msg := range subset.Messages {
+			index := len(snapshot.Rules)
+			snapshot.Rules = append(snapshot.Rules, Rule{
+				ID:          fmt.Sprintf("%s/%d", ref, i),
+				Event:       subset.Name,
+				Origin:      subset.Origin,
+				Priority:    subset.Priority,
+				MessageType: msg.Type,
+				Reply:       msg.Reply,
+				Variants:    msg.Replies,
+				Selection:   msg.Selection,
+				I18n:        msg.I18n,
+				CatchAll:    len(msg.Keywords) == 0 && len(msg.Patterns) == 0,
+
+				Source:          subset.Source,
+				MentionRequired: subset.MentionRequired,
+				Schedule:        subset.Schedule,
+			})
+			snapshot.Buckets[subset.Type] = append(snapshot.Buckets[subset.Type], index)
+
+			if subset.Type != linebot.EventTypeMessage {
+				continue
+			}
+
+			for _, keyword := range msg.Keywords {
+				if snapshot.Keywords == nil {
+					snapshot.Keywords = &TrieNode{}
+				}
+				snapshot.Keywords.insert(keyword, index)
+			}
+
+			for _, pattern := range msg.Patterns {
+				if _, err := regexp.Compile(pattern); err != nil {
+					return nil, fmt.Errorf("invalid pattern %q of %s: %v", pattern, ref, err)
+				}
+				snapshot.Patterns = append(snapshot.Patterns, Pattern{Expr: pattern, Rule: index})
+			}
+		}
+	}
+
+	for _, dialog := range dialogs {
+		if _, err := conversation.NewMachine(dialog.Name, &dialog.Spec); err != nil {
+			return nil, err
+		}
+		snapshot.Dialogs = append(snapshot.Dialogs, Dialog{Name: dialog.Name, Spec: dialog.Spec})
+	}
+	sort.Slice(snapshot.Dialogs, func(i, j int) bool {
+		return snapshot.Dialogs[i].Name < snapshot.Dialogs[j].Name
+	})
+
+	version, err := snapshot.hash()
+	if err != nil {
+		return nil, err
+	}
+	snapshot.Version = version
+	return snapshot, nil
+}
+
+// hash returns the hash of the rules, the generation is left out so that a
+// change of the eventbinding not changing the rules keeps the version.
+func (s *Snapshot) hash() (string, error) {
+	content := *s
+	content.Version = ""
+	content.Generation = 0
+
+	data, err := json.Marshal(content)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(data))[:16], nil
+}
+
+func (n *TrieNode) insert(keyword string, rule int) {
+	node := n
+	for _, r := range keyword {
+		if node.Children == nil {
+			node.Children = map[string]*TrieNode{}
+		}
+
+		child, ok := node.Children[string(r)]
+		if !ok {
+			child = &TrieNode{}
+			node.Children[string(r)] = child
+		}
+		node = child
+	}
+	node.Rules = append(node.Rules, rule)
+}
+
+// lookup returns the rules of a keyword equal to the text.
+func (n *TrieNode) lookup(text string) []int {
+	node := n
+	for _, r := range text {
+		if node == nil {
+			return nil
+		}
+		node = node.Children[string(r)]
+	}
+	if node == nil {
+		return nil
+	}
+	return node.Rules
+}
@@ -0,0 +1,224 @@
+package rules
+
+import (
+	"encoding/json"
+	"regexp"
+	"time"
+
+	linev1beta1 "github.com/kairen/line-bot-operator/pkg/apis/line/v1beta1"
+	"github.com/kairen/line-bot-operator/pkg/conversation"
+	"github.com/kairen/line-bot-operator/pkg/schedule"
+	"github.com/line/line-bot-sdk-go/linebot"
+)
+
+// Table is a loaded snapshot with the patterns compiled, it is read-only so a bot
+// can swap the whole table when the snapshot changes.
+type Table struct {
+	*Snapshot
+
+	patterns []*regexp.Regexp
+	machines []*conversation.Machine
+	// schedules are the compiled schedules by rule index, nil for the rules
+	// without a schedule.
+	schedules []*schedule.Schedule
+}
+
+// Load parses a snapshot and compiles its patterns.
+func Load(data []byte) (*Table, error) {
+	snapshot := &Snapshot{}
+	if err := json.Unmarshal(data, snapshot); err != nil {
+		return nil, err
+	}
+
+	table := &Table{Snapshot: snapshot}
+	for _, pattern := range snapshot.Patterns {
+		re, err := regexp.Compile(pattern.Expr)
+		if err != nil {
+			return nil, err
+		}
+		table.patterns = append(table.patterns, re)
+	}
+
+	for _, dialog := range snapshot.Dialogs {
+		m, err := conversation.NewMachine(dialog.Name, &dialog.Spec)
+		if err != nil {
+			return nil, err
+		}
+		table.machines = append(table.machines, m)
+	}
+
+	table.schedules = make([]*schedule.Schedule, len(snapshot.Rules))
+	for i, rule := range snapshot.Rules {
+		if rule.Schedule == nil {
+			continue
+		}
+		s, err := NewSchedule(rule.Schedule)
+		if err != nil {
+			return nil, err
+		}
+		table.schedules[i] = s
+	}
+	return table, nil
+}
+
+// NewSchedule compiles the schedule of a rule, whose holidays ConfigMap is
+// expected to be resolved.
+func NewSchedule(s *linev1beta1.EventSchedule) (*schedule.Schedule, error) {
+	spec := &schedule.Spec{
+		TimeZone:  s.TimeZone,
+		Windows:   s.Windows,
+		StartDate: s.StartDate,
+		EndDate:   s.EndDate,
+	}
+	if s.Holidays != nil {
+		spec.Holidays = s.Holidays.Dates
+		spec.ActiveOnHolidays = s.Holidays.Active
+	}
+	return schedule.New(spec)
+}
+
+// Converse runs the dialogs of the table, a bot replies with the result when it
+// isn't nil and matches the rules otherwise.
+func (t *Table) Converse(store conversation.Store, source string, in conversation.Input, now time.Time) (*conversation.Result, error) {
+	return conversation.Converse(store, t.machines, source, in, now)
+}
+
+// Request describes a webhook event to match. Mentioned is set when a message
+// mentions the bot, and Time is when the event was sent, now when zero.
+type Request struct {
+	EventType   linebot.EventType
+	MessageType linebot.MessageType
+	Text        string
+
+	SourceType linebot.EventSourceType
+	UserID     string
+	GroupID    string
+	RoomID     string
+	Mentioned  bool
+
+	Time time.Time
+}
+
+// Match returns the rule replying to a webhook event, or nil. The text is only
+// used by message events, a keyword has to be equal to it and a pattern has to
+// match it. Of all the matching rules allowed in the chat, the first one in
+// resolution order wins.
+func (t *Table) Match(req *Request) *Rule {
+	if req.EventType != linebot.EventTypeMessage {
+		for _, index := range t.Buckets[req.EventType] {
+			if t.allows(index, req) {
+				return &t.Rules[index]
+			}
+		}
+		return nil
+	}
+
+	match := -1
+	if t.Keywords != nil {
+		match = t.first(match, t.Keywords.lookup(req.Text), req)
+	}
+
+	for i, re := range t.patterns {
+		if re.MatchString(req.Text) {
+			match = t.first(match, []int{t.Patterns[i].Rule}, req)
+		}
+	}
+
+	for _, index := range t.Buckets[req.EventType] {
+		if t.Rules[index].CatchAll {
+			match = t.first(match, []int{index}, req)
+		}
+	}
+
+	if match < 0 {
+		return nil
+	}
+	return &t.Rules[match]
+}
+
+// first returns the lowest of match and the candidate rules allowing the request.
+func (t *Table) first(match int, candidates []int, req *Request) int {
+	for _, index := range candidates {
+		rule := &t.Rules[index]
+		if rule.MessageType != "" && rule.MessageType != req.MessageType {
+			continue
+		}
+		if !t.allows(index, req) {
+			continue
+		}
+		if match < 0 || index < match {
+			match = index
+		}
+	}
+	return match
+}
+
+// allows reports whether a rule is active at the time of the request and replies
+// in its chat.
+func (t *Table) allows(index int, req *Request) bool {
+	if s := t.schedules[index]; s != nil {
+		now := req.Time
+		if now.IsZero() {
+			now = time.Now()
+		}
+		if !s.Active(now) {
+			return false
+		}
+	}
+	return t.Rules[index].allows(req)
+}
+
+// allows reports whether the rule replies in the chat of the request.
+func (r *Rule) allows(req *Request) bool {
+	if r.MentionRequired && req.SourceType != linebot.EventSourceTypeUser && !req.Mentioned {
+		return false
+	}
+	if r.Source == nil {
+		return true
+	}
+
+	if len(r.Source.Types) > 0 && !containsType(r.Source.Types, req.SourceType) {
+		return false
+	}
+
+	ids := []string{}
+	for _, id := range []string{req.UserID, req.GroupID, req.RoomID} {
+		if id != "" {
+			ids = append(ids, id)
+		}
+	}
+
+	for _, id := range ids {
+		if contains(r.Source.Deny, id) {
+			return false
+		}
+	}
+
+	if len(r.Source.Allow) == 0 {
+		return true
+	}
+	for _, id := range ids {
+		if contains(r.Source.Allow, id) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsType(types []linebot.EventSourceType, t linebot.EventSourceType) bool {
+	for _, item := range types {
+		if item == t {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(ids []string, id string) bool {
+	for _, item := range ids {
+		if item == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,205 @@
+package rules
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	linev1beta1 "github.com/kairen/line-bot-operator/pkg/apis/line/v1beta1"
+)
+
+const (
+	// pruneInterval is how often the picks of the idle sources are removed.
+	pruneInterval = time.Minute
+	// idleTimeout is the time after which a source starts over, e.g. from the
+	// first variant of a round robin.
+	idleTimeout = 24 * time.Hour
+)
+
+// Picker picks the reply variants of the rules. It keeps the picks of each rule
+// and source in memory, so a bot should keep one picker across the tables it
+// swaps, and call Retain with each new table. It is safe for concurrent use.
+type Picker struct {
+	mu sync.Mutex
+	// rand is used by the rules without a seed, seeded has a source by rule ID and
+	// seed.
+	rand    *rand.Rand
+	seeded  map[string]*rand.Rand
+	counts  map[string]int
+	history map[string][]int
+	// used is the time of the last pick by key of counts and history.
+	used      map[string]time.Time
+	lastPrune time.Time
+	now       func() time.Time
+}
+
+func NewPicker() *Picker {
+	return &Picker{
+		rand:    rand.New(rand.NewSource(time.Now().UnixNano())),
+		seeded:  map[string]*rand.Rand{},
+		counts:  map[string]int{},
+		history: map[string][]int{},
+		used:    map[string]time.Time{},
+		now:     time.Now,
+	}
+}
+
+// Reply returns the reply of a rule for a source, i.e. a user, group or room ID,
+// which is one of its variants when it has some, or the reply localized for the
+// language of the user.
+func (p *Picker) Reply(rule *Rule, source, language string) []linev1beta1.ReplyMessage {
+	if len(rule.Variants) == 0 {
+		return rule.Localize(language)
+	}
+
+	selection := rule.Selection
+	if selection == nil {
+		selection = &linev1beta1.ReplySelection{}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.now()
+	p.prune(now)
+
+	key := rule.ID + "\x00" + source
+	var index int
+	switch selection.Strategy {
+	case linev1beta1.SelectionRoundRobin:
+		index = roundRobin(rule.Variants, p.counts[key])
+		p.counts[key]++
+		p.used[key] = now
+	case linev1beta1.SelectionNoRepeat:
+		window := int(selection.Window)
+		if window == 0 {
+			window = 1
+		}
+		index = pick(p.randFor(rule.ID, selection), rule.Variants, p.history[key])
+		p.history[key] = append(p.history[key], index)
+		if len(p.history[key]) > window {
+			p.history[key] = p.history[key][1:]
+		}
+		p.used[key] = now
+	default:
+		index = pick(p.randFor(rule.ID, selection), rule.Variants, nil)
+	}
+	return rule.Variants[index].Reply
+}
+
+func (p *Picker) randFor(id string, selection *linev1beta1.ReplySelection) *rand.Rand {
+	if selection.Seed == nil {
+		return p.rand
+	}
+
+	key := fmt.Sprintf("%s\x00%d", id, *selection.Seed)
+	r, ok := p.seeded[key]
+	if !ok {
+		r = rand.New(rand.NewSource(*selection.Seed))
+		p.seeded[key] = r
+	}
+	return r
+}
+
+// Retain drops the picks of the rules missing from a table, e.g. of an unbound
+// event.
+func (p *Picker) Retain(table *Table) {
+	ids := map[string]bool{}
+	for i := range table.Rules {
+		ids[table.Rules[i].ID] = true
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for key := range p.used {
+		if !ids[ruleID(key)] {
+			p.forget(key)
+		}
+	}
+	for key := range p.seeded {
+		if !ids[ruleID(key)] {
+			delete(p.seeded, key)
+		}
+	}
+}
+
+func (p *Picker) prune(now time.Time) {
+	if now.Sub(p.lastPrune) < pruneInterval {
+		return
+	}
+	p.lastPrune = now
+
+	for key, used := range p.used {
+		if now.Sub(used) >= idleTimeout {
+			p.forget(key)
+		}
+	}
+}
+
+func (p *Picker) forget(key string) {
+	delete(p.counts, key)
+	delete(p.history, key)
+	delete(p.used, key)
+}
+
+// ruleID returns the rule ID of a key, the rule IDs never hold a NUL.
+func ruleID(key string) string {
+	return key[:strings.IndexByte(key, 0)]
+}
+
+// weight defaults to 1. The negative weights, only rejected by the optional
+// webhook, count as 1 too, so that the weights never sum to 0.
+func weight(variant *linev1beta1.ReplyVariant) int {
+	if variant.Weight <= 0 {
+		return 1
+	}
+	return int(variant.Weight)
+}
+
+// roundRobin returns the variant of the nth reply, each variant is repeated as
+// many times as its weight.
+func roundRobin(variants []linev1beta1.ReplyVariant, n int) int {
+	total := 0
+	for i := range variants {
+		total += weight(&variants[i])
+	}
+
+	n %= total
+	for i := range variants {
+		if n -= weight(&variants[i]); n < 0 {
+			return i
+		}
+	}
+	return 0
+}
+
+// pick returns a variant at random by weight, leaving out the excluded ones
+// unless they are all excluded.
+func pick(r *rand.Rand, variants []linev1beta1.ReplyVariant, excluded []int) int {
+	skip := map[int]bool{}
+	for _, i := range excluded {
+		skip[i] = true
+	}
+	if len(skip) >= len(variants) {
+		skip = map[int]bool{}
+	}
+
+	total := 0
+	for i := range variants {
+		if !skip[i] {
+			total += weight(&variants[i])
+		}
+	}
+
+	n := r.Intn(total)
+	for i := range variants {
+		if skip[i] {
+			continue
+		}
+		if n -= weight(&variants[i]); n < 0 {
+			return i
+		}
+	}
+	return 0
+}
@@ -0,0 +1,90 @@
+package rules
+
+import (
+	"testing"
+	"time"
+
+	linev1beta1 "github.com/kairen/line-bot-operator/pkg/apis/line/v1beta1"
+)
+
+func TestPickerWeights(t *testing.T) {
+	variants := []linev1beta1.ReplyVariant{
+		{Weight: -1, Reply: []linev1beta1.ReplyMessage{{Type: "text", Text: "a"}}},
+		{Weight: 0, Reply: []linev1beta1.ReplyMessage{{Type: "text", Text: "b"}}},
+	}
+	strategies := []linev1beta1.SelectionStrategy{
+		linev1beta1.SelectionRandom,
+		linev1beta1.SelectionRoundRobin,
+		linev1beta1.SelectionNoRepeat,
+	}
+
+	// Weights not checked by a webhook must not crash the bot.
+	p := NewPicker()
+	seed := int64(1)
+	for _, strategy := range strategies {
+		rule := &Rule{ID: "hello/0", Variants: variants, Selection: &linev1beta1.ReplySelection{Strategy: strategy, Seed: &seed}}
+		seen := map[string]bool{}
+		for i := 0; i < 20; i++ {
+			seen[p.Reply(rule, "user", "")[0].Text] = true
+		}
+		if !seen["a"] || !seen["b"] {
+			t.Errorf("%s: got replies %v, want both variants", strategy, seen)
+		}
+	}
+}
+
+func roundRobinRule(id string) *Rule {
+	return &Rule{
+		ID: id,
+		Variants: []linev1beta1.ReplyVariant{
+			{Reply: []linev1beta1.ReplyMessage{{Type: "text", Text: "a"}}},
+			{Reply: []linev1beta1.ReplyMessage{{Type: "text", Text: "b"}}},
+		},
+		Selection: &linev1beta1.ReplySelection{Strategy: linev1beta1.SelectionRoundRobin},
+	}
+}
+
+func TestPickerPrune(t *testing.T) {
+	now := time.Date(2019, 4, 1, 12, 0, 0, 0, time.UTC)
+	p := NewPicker()
+	p.now = func() time.Time { return now }
+
+	rule := roundRobinRule("hello/0")
+	p.Reply(rule, "alice", "")
+	now = now.Add(idleTimeout / 2)
+	p.Reply(rule, "bob", "")
+
+	// Alice is idle, bob isn't yet.
+	now = now.Add(idleTimeout / 2)
+	if got := p.Reply(rule, "bob", "")[0].Text; got != "b" {
+		t.Errorf("got %q for bob, want the round robin to go on", got)
+	}
+	if _, ok := p.counts["hello/0\x00alice"]; ok || len(p.counts) != 1 {
+		t.Errorf("got counts %v, want bob only", p.counts)
+	}
+	if got := p.Reply(rule, "alice", "")[0].Text; got != "a" {
+		t.Errorf("got %q for alice, want the round robin to start over", got)
+	}
+}
+
+func TestPickerRetain(t *testing.T) {
+	p := NewPicker()
+	seed := int64(1)
+	for _, id := range []string{"hello/0", "bye/0"} {
+		rule := roundRobinRule(id)
+		p.Reply(rule, "alice", "")
+		rule.Selection = &linev1beta1.ReplySelection{Strategy: linev1beta1.SelectionNoRepeat, Seed: &seed}
+		p.Reply(rule, "bob", "")
+	}
+
+	p.Retain(&Table{Snapshot: &Snapshot{Rules: []Rule{{ID: "hello/0"}}}})
+	if len(p.counts) != 1 || len(p.history) != 1 || len(p.seeded) != 1 || len(p.used) != 2 {
+		t.Errorf("got %d counts, %d histories, %d seeded and %d used picks, want 1, 1, 1 and 2",
+			len(p.counts), len(p.history), len(p.seeded), len(p.used))
+	}
+	for key := range p.used {
+		if ruleID(key) != "hello/0" {
+			t.Errorf("got the picks of %s, want them dropped", ruleID(key))
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCron(t *testing.T) {
+	// 2019-03-03 is a Sunday.
+	at := func(value string) time.Time {
+		tm, err := time.Parse("2006-01-02 15:04", value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tm
+	}
+
+	tests := []struct {
+		expr    string
+		time    string
+		matches bool
+	}{
+		{"* * * * *", "2019-03-03 00:00", true},
+		{"0-30 9-17 * * *", "2019-03-04 09:30", true},
+		{"0-30 9-17 * * *", "2019-03-04 09:31", false},
+		{"0-30 9-17 * * *", "2019-03-04 18:00", false},
+		{"*/15 * * * *", "2019-03-04 10:45", true},
+		{"*/15 * * * *", "2019-03-04 10:50", false},
+		{"10-50/20 * * * *", "2019-03-04 10:30", true},
+		{"10-50/20 * * * *", "2019-03-04 10:40", false},
+		{"5/10 * * * *", "2019-03-04 10:55", true},
+		{"5/10 * * * *", "2019-03-04 10:05", true},
+		{"5/10 * * * *", "2019-03-04 10:10", false},
+		{"* 20/2 * * *", "2019-03-04 22:00", true},
+		{"0 12 * * 1,3,5", "2019-03-06 12:00", true},
+		{"0 12 * * 1,3,5", "2019-03-05 12:00", false},
+		// Sunday is both 0 and 7.
+		{"* * * * 7", "2019-03-03 08:00", true},
+		{"* * * * 0", "2019-03-03 08:00", true},
+		{"* * * * 6-7", "2019-03-03 08:00", true},
+		{"* * * * 1-6", "2019-03-03 08:00", false},
+		// Either day matches when both are restricted.
+		{"* * 1 * 1", "2019-03-01 08:00", true},
+		{"* * 1 * 1", "2019-03-04 08:00", true},
+		{"* * 1 * 1", "2019-03-05 08:00", false},
+		// Both days have to match when one of them is *.
+		{"* * 1 * *", "2019-03-04 08:00", false},
+		{"* * * * 1", "2019-03-01 08:00", false},
+		{"* * * 3 *", "2019-04-01 08:00", false},
+	}
+
+	for _, test := range tests {
+		c, err := parseCron(test.expr)
+		if err != nil {
+			t.Errorf("%q: %v", test.expr, err)
+			continue
+		}
+		if got := c.matches(at(test.time)); got != test.matches {
+			t.Errorf("%q at %s: got %v, want %v", test.expr, test.time, got, test.matches)
+		}
+	}
+}
+
+func TestParseCronErrors(t *testing.T) {
+	for _, expr := range []string{
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"30-10 * * * *",
+		"*/0 * * * *",
+		"60/5 * * * *",
+		"a * * * *",
+		"1-b * * * *",
+	} {
+		if _, err := parseCron(expr); err == nil {
+			t.Errorf("%q: expected an error", expr)
+		}
+	}
+}
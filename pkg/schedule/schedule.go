@@ -0,0 +1,105 @@
+package schedule
+
+import (
+	"fmt"
+	"time"
+)
+
+// DateLayout is the layout of the start, end and holiday dates.
+const DateLayout = "2006-01-02"
+
+// Spec is the schedule of an Event, see EventSchedule of the API.
+type Spec struct {
+	TimeZone         string
+	Windows          []string
+	StartDate        string
+	EndDate          string
+	Holidays         []string
+	ActiveOnHolidays bool
+}
+
+// Schedule tells whether an event is active at a given time.
+type Schedule struct {
+	location         *time.Location
+	windows          []*cron
+	start, end       string
+	holidays         map[string]bool
+	activeOnHolidays bool
+}
+
+// New checks and compiles a schedule.
+func New(spec *Spec) (*Schedule, error) {
+	location, err := time.LoadLocation(spec.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time zone %q: %v", spec.TimeZone, err)
+	}
+
+	s := &Schedule{
+		location:         location,
+		start:            spec.StartDate,
+		end:              spec.EndDate,
+		holidays:         map[string]bool{},
+		activeOnHolidays: spec.ActiveOnHolidays,
+	}
+
+	for _, window := range spec.Windows {
+		c, err := parseCron(window)
+		if err != nil {
+			return nil, err
+		}
+		s.windows = append(s.windows, c)
+	}
+
+	for _, date := range append([]string{spec.StartDate, spec.EndDate}, spec.Holidays...) {
+		if _, err := time.Parse(DateLayout, date); date != "" && err != nil {
+			return nil, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", date)
+		}
+	}
+	if s.start != "" && s.end != "" && s.start > s.end {
+		return nil, fmt.Errorf("start date %s is after end date %s", s.start, s.end)
+	}
+
+	for _, date := range spec.Holidays {
+		s.holidays[date] = true
+	}
+	return s, nil
+}
+
+// Active reports whether the schedule is active at t: on a day between the start
+// and end dates, all day on holidays when they are active, and otherwise in one
+// of the windows.
+func (s *Schedule) Active(t time.Time) bool {
+	t = t.In(s.location)
+	date := t.Format(DateLayout)
+	if (s.start != "" && date < s.start) || (s.end != "" && date > s.end) {
+		return false
+	}
+
+	if s.holidays[date] {
+		return s.activeOnHolidays
+	}
+
+	if len(s.windows) == 0 {
+		return true
+	}
+	for _, window := range s.windows {
+		if window.matches(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// NextChange returns the next minute the schedule switches on or off, or false
+// when it doesn't change within the duration after t.
+func (s *Schedule) NextChange(t time.Time, within time.Duration) (time.Time, bool) {
+	active := s.Active(t)
+	next := t.Truncate(time.Minute)
+	for i := 0; i < int(within/time.Minute); i++ {
+		next = next.Add(time.Minute)
+		if s.Active(next) != active {
+			return next, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,160 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	linev1alpha1 "github.com/kairen/line-bot-operator/pkg/apis/line/v1alpha1"
+	linev1beta1 "github.com/kairen/line-bot-operator/pkg/apis/line/v1beta1"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog"
+)
+
+func (s *Server) convert(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	review := &apiextensionsv1beta1.ConversionReview{}
+	if err := json.Unmarshal(body, review); err != nil || review.Request == nil {
+		http.Error(w, fmt.Sprintf("could not decode conversion review: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	response := &apiextensionsv1beta1.ConversionResponse{
+		UID:    review.Request.UID,
+		Result: metav1.Status{Status: metav1.StatusSuccess},
+	}
+	for _, obj := range review.Request.Objects {
+		converted, err := convertObject(obj.Raw, review.Request.DesiredAPIVersion)
+		if err != nil {
+			klog.Errorf("Failed to convert object to %s: %+v.", review.Request.DesiredAPIVersion, err)
+			response.ConvertedObjects = nil
+			response.Result = metav1.Status{Status: metav1.StatusFailure, Message: err.Error()}
+			break
+		}
+		response.ConvertedObjects = append(response.ConvertedObjects, runtime.RawExtension{Raw: converted})
+	}
+
+	review.Request = nil
+	review.Response = response
+	resp, err := json.Marshal(review)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(resp); err != nil {
+		klog.Errorf("Failed to write conversion response: %+v.", err)
+	}
+}
+
+func convertObject(raw []byte, desiredAPIVersion string) ([]byte, error) {
+	typeMeta := &metav1.TypeMeta{}
+	if err := json.Unmarshal(raw, typeMeta); err != nil {
+		return nil, err
+	}
+
+	if typeMeta.APIVersion == desiredAPIVersion {
+		return raw, nil
+	}
+
+	gv, err := schema.ParseGroupVersion(typeMeta.APIVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	obj, err := decodeV1alpha1(typeMeta.Kind, gv.Version, raw)
+	if err != nil {
+		return nil, err
+	}
+
+	switch desiredAPIVersion {
+	case linev1alpha1.SchemeGroupVersion.String():
+	case linev1beta1.SchemeGroupVersion.String():
+		if obj, err = encodeV1beta1(obj); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported API version %q", desiredAPIVersion)
+	}
+
+	obj.GetObjectKind().SetGroupVersionKind(schema.FromAPIVersionAndKind(desiredAPIVersion, typeMeta.Kind))
+	return json.Marshal(obj)
+}
+
+// decodeV1alpha1 decodes an object of the given version into its v1alpha1 form, which
+// is the version the operator works with.
+func decodeV1alpha1(kind, version string, raw []byte) (runtime.Object, error) {
+	switch version {
+	case linev1alpha1.Version:
+		var obj runtime.Object
+		switch kind {
+		case "Bot":
+			obj = &linev1alpha1.Bot{}
+		case "Event":
+			obj = &linev1alpha1.Event{}
+		case "EventBinding":
+			obj = &linev1alpha1.EventBinding{}
+		case "ClusterEvent":
+			obj = &linev1alpha1.ClusterEvent{}
+		default:
+			return nil, fmt.Errorf("unsupported kind %q", kind)
+		}
+		return obj, json.Unmarshal(raw, obj)
+	case linev1beta1.Version:
+		switch kind {
+		case "Bot":
+			in, out := &linev1beta1.Bot{}, &linev1alpha1.Bot{}
+			if err := json.Unmarshal(raw, in); err != nil {
+				return nil, err
+			}
+			return out, linev1beta1.Convert_v1beta1_Bot_To_v1alpha1_Bot(in, out)
+		case "Event":
+			in, out := &linev1beta1.Event{}, &linev1alpha1.Event{}
+			if err := json.Unmarshal(raw, in); err != nil {
+				return nil, err
+			}
+			return out, linev1beta1.Convert_v1beta1_Event_To_v1alpha1_Event(in, out)
+		case "EventBinding":
+			in, out := &linev1beta1.EventBinding{}, &linev1alpha1.EventBinding{}
+			if err := json.Unmarshal(raw, in); err != nil {
+				return nil, err
+			}
+			return out, linev1beta1.Convert_v1beta1_EventBinding_To_v1alpha1_EventBinding(in, out)
+		case "ClusterEvent":
+			in, out := &linev1beta1.ClusterEvent{}, &linev1alpha1.ClusterEvent{}
+			if err := json.Unmarshal(raw, in); err != nil {
+				return nil, err
+			}
+			return out, linev1beta1.Convert_v1beta1_ClusterEvent_To_v1alpha1_ClusterEvent(in, out)
+		}
+		return nil, fmt.Errorf("unsupported kind %q", kind)
+	}
+	return nil, fmt.Errorf("unsupported version %q", version)
+}
+
+func encodeV1beta1(obj runtime.Object) (runtime.Object, error) {
+	switch in := obj.(type) {
+	case *linev1alpha1.Bot:
+		out := &linev1beta1.Bot{}
+		return out, linev1beta1.Convert_v1alpha1_Bot_To_v1beta1_Bot(in, out)
+	case *linev1alpha1.Event:
+		out := &linev1beta1.Event{}
+		return out, linev1beta1.Convert_v1alpha1_Event_To_v1beta1_Event(in, out)
+	case *linev1alpha1.EventBinding:
+		out := &linev1beta1.EventBinding{}
+		return out, linev1beta1.Convert_v1alpha1_EventBinding_To_v1beta1_EventBinding(in, out)
+	case *linev1alpha1.ClusterEvent:
+		out := &linev1beta1.ClusterEvent{}
+		return out, linev1beta1.Convert_v1alpha1_ClusterEvent_To_v1beta1_ClusterEvent(in, out)
+	}
+	return nil, fmt.Errorf("unsupported object %T", obj)
+}
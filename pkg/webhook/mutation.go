@@ -0,0 +1,49 @@
+package webhook
+
+import (
+	"encoding/json"
+	"reflect"
+
+	linev1alpha1 "github.com/kairen/line-bot-operator/pkg/apis/line/v1alpha1"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+)
+
+type patchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+func (s *Server) mutate(req *admissionv1beta1.AdmissionRequest) *admissionv1beta1.AdmissionResponse {
+	if req.Operation == admissionv1beta1.Delete || req.Kind.Kind != "Bot" {
+		return allowed()
+	}
+
+	obj, err := decodeV1alpha1(req.Kind.Kind, req.Kind.Version, req.Object.Raw)
+	if err != nil {
+		return denied(err)
+	}
+	bot := obj.(*linev1alpha1.Bot)
+
+	defaulted := bot.DeepCopy()
+	s.config.Get().SetBotDefaults(defaulted)
+	if reflect.DeepEqual(bot.Spec, defaulted.Spec) {
+		return allowed()
+	}
+
+	// The spec may be absent from the request, so replace it as a whole. The Bot spec
+	// has the same shape in every served version.
+	patch, err := json.Marshal([]patchOperation{
+		{Op: "add", Path: "/spec", Value: defaulted.Spec},
+	})
+	if err != nil {
+		return denied(err)
+	}
+
+	patchType := admissionv1beta1.PatchTypeJSONPatch
+	return &admissionv1beta1.AdmissionResponse{
+		Allowed:   true,
+		Patch:     patch,
+		PatchType: &patchType,
+	}
+}
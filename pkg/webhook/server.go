@@ -0,0 +1,122 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/kairen/line-bot-operator/pkg/config"
+	clientset "github.com/kairen/line-bot-operator/pkg/generated/clientset/versioned"
+	opkit "github.com/kubedev/operator-kit"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog"
+)
+
+const (
+	ValidatePath = "/validate"
+	MutatePath   = "/mutate"
+	ConvertPath  = "/convert"
+
+	shutdownTimeout = 5 * time.Second
+)
+
+type admitFunc func(*admissionv1beta1.AdmissionRequest) *admissionv1beta1.AdmissionResponse
+
+type Server struct {
+	ctx       *opkit.Context
+	clientset clientset.Interface
+	config    *config.Store
+	server    *http.Server
+	certFile  string
+	keyFile   string
+}
+
+func NewServer(ctx *opkit.Context, clientset clientset.Interface, config *config.Store, addr, certFile, keyFile string) *Server {
+	s := &Server{
+		ctx:       ctx,
+		clientset: clientset,
+		config:    config,
+		certFile:  certFile,
+		keyFile:   keyFile,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(ValidatePath, s.serve(s.validate))
+	mux.HandleFunc(MutatePath, s.serve(s.mutate))
+	mux.HandleFunc(ConvertPath, s.convert)
+	s.server = &http.Server{Addr: addr, Handler: mux}
+	return s
+}
+
+func (s *Server) Run(stopCh chan struct{}) {
+	go func() {
+		klog.Infof("Start serving webhook on %s.", s.server.Addr)
+		if err := s.server.ListenAndServeTLS(s.certFile, s.keyFile); err != nil && err != http.ErrServerClosed {
+			klog.Errorf("Failed to serve webhook: %+v.", err)
+		}
+	}()
+
+	go func() {
+		<-stopCh
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := s.server.Shutdown(ctx); err != nil {
+			klog.Errorf("Failed to shutdown webhook: %+v.", err)
+		}
+	}()
+}
+
+func (s *Server) serve(admit admitFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			http.Error(w, "invalid Content-Type, expect `application/json`", http.StatusUnsupportedMediaType)
+			return
+		}
+
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		review := &admissionv1beta1.AdmissionReview{}
+		if err := json.Unmarshal(body, review); err != nil || review.Request == nil {
+			http.Error(w, fmt.Sprintf("could not decode admission review: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		response := admit(review.Request)
+		response.UID = review.Request.UID
+		review.Response = response
+		review.Request = nil
+
+		resp, err := json.Marshal(review)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if _, err := w.Write(resp); err != nil {
+			klog.Errorf("Failed to write admission response: %+v.", err)
+		}
+	}
+}
+
+func allowed() *admissionv1beta1.AdmissionResponse {
+	return &admissionv1beta1.AdmissionResponse{Allowed: true}
+}
+
+func denied(err error) *admissionv1beta1.AdmissionResponse {
+	return &admissionv1beta1.AdmissionResponse{
+		Allowed: false,
+		Result: &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Reason:  metav1.StatusReasonInvalid,
+			Message: err.Error(),
+		},
+	}
+}
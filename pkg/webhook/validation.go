@@ -0,0 +1,366 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	linev1alpha1 "github.com/kairen/line-bot-operator/pkg/apis/line/v1alpha1"
+	linev1beta1 "github.com/kairen/line-bot-operator/pkg/apis/line/v1beta1"
+	"github.com/kairen/line-bot-operator/pkg/apis/line/validation"
+	"github.com/kairen/line-bot-operator/pkg/constants"
+	"github.com/line/line-bot-sdk-go/linebot"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+var supportedTokenSourceTypes = []string{
+	string(linev1alpha1.StaticTokenSource),
+	string(linev1alpha1.ClientSecretTokenSource),
+	string(linev1alpha1.AssertionTokenSource),
+}
+
+var supportedExposeTypes = []string{
+	string(linev1alpha1.NgrokExpose),
+	string(linev1alpha1.IngressExpose),
+	string(linev1alpha1.LoadBalancerExpose),
+}
+
+func (s *Server) validate(req *admissionv1beta1.AdmissionRequest) *admissionv1beta1.AdmissionResponse {
+	if req.Operation == admissionv1beta1.Delete || req.Kind.Group != linev1alpha1.CustomResourceGroup {
+		return allowed()
+	}
+
+	// Dialogs are only served as v1beta1.
+	if req.Kind.Kind == "Dialog" {
+		dialog := &linev1beta1.Dialog{}
+		if err := json.Unmarshal(req.Object.Raw, dialog); err != nil {
+			return denied(err)
+		}
+		if errs := s.validateDialog(dialog); len(errs) > 0 {
+			return denied(errs.ToAggregate())
+		}
+		return allowed()
+	}
+
+	obj, err := decodeV1alpha1(req.Kind.Kind, req.Kind.Version, req.Object.Raw)
+	if err != nil {
+		return denied(err)
+	}
+
+	var errs field.ErrorList
+	switch o := obj.(type) {
+	case *linev1alpha1.Bot:
+		var old *linev1alpha1.Bot
+		if req.Operation == admissionv1beta1.Update {
+			oldObj, err := decodeV1alpha1(req.Kind.Kind, req.Kind.Version, req.OldObject.Raw)
+			if err != nil {
+				return denied(err)
+			}
+			old = oldObj.(*linev1alpha1.Bot)
+		}
+		errs = s.validateBot(o, old)
+	case *linev1alpha1.Event:
+		errs = s.validateEvent(o)
+	case *linev1alpha1.EventBinding:
+		errs = s.validateEventBinding(o)
+	case *linev1alpha1.ClusterEvent:
+		errs = s.validateClusterEvent(o)
+	}
+
+	if len(errs) > 0 {
+		return denied(errs.ToAggregate())
+	}
+	return allowed()
+}
+
+// validateBot validates a bot, old is the bot being updated or nil on create.
+func (s *Server) validateBot(bot, old *linev1alpha1.Bot) field.ErrorList {
+	errs := field.ErrorList{}
+	specPath := field.NewPath("spec")
+
+	if bot.Spec.Selector == nil {
+		errs = append(errs, field.Required(specPath.Child("selector"), "must select the events to bind"))
+	}
+
+	exposePath := specPath.Child("expose")
+	switch bot.Spec.Expose.Type {
+	case "", linev1alpha1.NgrokExpose, linev1alpha1.LoadBalancerExpose:
+	case linev1alpha1.IngressExpose:
+		if bot.Spec.Expose.DomainName == "" {
+			errs = append(errs, field.Required(exposePath.Child("domainName"), "Ingress expose requires a domain name"))
+		}
+	default:
+		errs = append(errs, field.NotSupported(exposePath.Child("type"), bot.Spec.Expose.Type, supportedExposeTypes))
+	}
+
+	isNgrok := isNgrokExpose(bot)
+	if bot.Spec.Replicas != nil {
+		if *bot.Spec.Replicas < 1 {
+			errs = append(errs, field.Invalid(specPath.Child("replicas"), *bot.Spec.Replicas, "must be greater than 0"))
+		} else if isNgrok && *bot.Spec.Replicas > 1 {
+			errs = append(errs, field.Forbidden(specPath.Child("replicas"), "Ngrok expose can't run more than one replica"))
+		}
+	}
+
+	if autoscaling := bot.Spec.Autoscaling; autoscaling != nil {
+		autoscalingPath := specPath.Child("autoscaling")
+		if isNgrok {
+			errs = append(errs, field.Forbidden(autoscalingPath, "Ngrok expose can't be autoscaled"))
+		}
+
+		minReplicas := int32(1)
+		if autoscaling.MinReplicas != nil {
+			minReplicas = *autoscaling.MinReplicas
+			if minReplicas < 1 {
+				errs = append(errs, field.Invalid(autoscalingPath.Child("minReplicas"), minReplicas, "must be greater than 0"))
+			}
+		}
+		if autoscaling.MaxReplicas < minReplicas {
+			errs = append(errs, field.Invalid(autoscalingPath.Child("maxReplicas"), autoscaling.MaxReplicas,
+				"must be greater than or equal to minReplicas"))
+		}
+	}
+
+	managedToken := false
+	if source := bot.Spec.TokenSource; source != nil {
+		sourcePath := specPath.Child("tokenSource")
+		switch source.Type {
+		case "", linev1alpha1.StaticTokenSource:
+		case linev1alpha1.ClientSecretTokenSource, linev1alpha1.AssertionTokenSource:
+			managedToken = true
+			if source.ChannelID == "" {
+				errs = append(errs, field.Required(sourcePath.Child("channelID"), "must be set to issue channel tokens"))
+			}
+		default:
+			errs = append(errs, field.NotSupported(sourcePath.Child("type"), source.Type, supportedTokenSourceTypes))
+		}
+
+		if source.Type == linev1alpha1.AssertionTokenSource && source.PrivateKeySecretName == "" {
+			errs = append(errs, field.Required(sourcePath.Child("privateKeySecretName"), "must reference the private key to sign assertions"))
+		}
+
+		if lifetime := source.TokenLifetime; lifetime != nil && (lifetime.Duration <= 0 || lifetime.Duration > maxTokenLifetime) {
+			errs = append(errs, field.Invalid(sourcePath.Child("tokenLifetime"), lifetime.Duration.String(), "must be between 0 and 30 days"))
+		}
+	}
+
+	errs = append(errs, validateRateLimit(bot.Spec.RateLimit, specPath.Child("rateLimit"))...)
+
+	if bot.Spec.PodTemplate != nil {
+		containersPath := specPath.Child("podTemplate", "spec", "containers")
+		for i, container := range bot.Spec.PodTemplate.Spec.Containers {
+			if container.Name == "" {
+				errs = append(errs, field.Required(containersPath.Index(i).Child("name"), "containers are merged by name"))
+			}
+		}
+	}
+
+	secretPath := specPath.Child("channelSecretName")
+	if bot.Spec.ChannelSecretName == "" {
+		return append(errs, field.Required(secretPath, "must reference the channel secret"))
+	}
+
+	// The secret is only checked when it's referenced, so that a bot can still
+	// be updated, or finalized, after its secret was deleted.
+	if old != nil && !secretChanged(old, bot) {
+		return errs
+	}
+
+	secret, err := s.ctx.Clientset.CoreV1().Secrets(bot.Namespace).Get(bot.Spec.ChannelSecretName, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return append(errs, field.NotFound(secretPath, bot.Spec.ChannelSecretName))
+		}
+		return append(errs, field.InternalError(secretPath, err))
+	}
+
+	keys := []string{constants.ChannelSecretKey}
+	if !managedToken {
+		keys = append(keys, constants.ChannelTokenKey)
+	}
+
+	for _, key := range keys {
+		if len(secret.Data[key]) == 0 {
+			errs = append(errs, field.Invalid(secretPath, bot.Spec.ChannelSecretName, fmt.Sprintf("secret has no %q key", key)))
+		}
+	}
+
+	if isNgrok && len(secret.Data[constants.NgrokTokenKey]) == 0 {
+		errs = append(errs, field.Required(exposePath,
+			fmt.Sprintf("Ngrok expose requires a token, secret %q has no %q key", secret.Name, constants.NgrokTokenKey)))
+	}
+	return errs
+}
+
+// secretChanged returns true when the update changes the channel secret, or
+// the keys it must hold.
+func secretChanged(old, bot *linev1alpha1.Bot) bool {
+	return old.Spec.ChannelSecretName != bot.Spec.ChannelSecretName ||
+		isManagedToken(old) != isManagedToken(bot) ||
+		isNgrokExpose(old) != isNgrokExpose(bot)
+}
+
+func isManagedToken(bot *linev1alpha1.Bot) bool {
+	source := bot.Spec.TokenSource
+	return source != nil &&
+		(source.Type == linev1alpha1.ClientSecretTokenSource || source.Type == linev1alpha1.AssertionTokenSource)
+}
+
+func isNgrokExpose(bot *linev1alpha1.Bot) bool {
+	return bot.Spec.Expose.Type == "" || bot.Spec.Expose.Type == linev1alpha1.NgrokExpose
+}
+
+// maxTokenLifetime is the longest lifetime of a channel access token v2.1.
+const maxTokenLifetime = 30 * 24 * time.Hour
+
+var supportedRateLimitActions = []string{
+	string(linev1alpha1.RateLimitDrop),
+	string(linev1alpha1.RateLimitNotice),
+}
+
+func validateRateLimit(limit *linev1alpha1.BotRateLimit, path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	if limit == nil {
+		return errs
+	}
+
+	if bucket := limit.PerSource; bucket != nil {
+		if bucket.Capacity < 1 {
+			errs = append(errs, field.Invalid(path.Child("perSource", "capacity"), bucket.Capacity, "must be greater than 0"))
+		}
+		if bucket.RefillInterval.Duration <= 0 {
+			errs = append(errs, field.Invalid(path.Child("perSource", "refillInterval"), bucket.RefillInterval.Duration.String(), "must be greater than 0"))
+		}
+	}
+
+	if limit.RepliesPerSecond < 0 {
+		errs = append(errs, field.Invalid(path.Child("repliesPerSecond"), limit.RepliesPerSecond, "must be greater than or equal to 0"))
+	}
+	if limit.Burst < 0 {
+		errs = append(errs, field.Invalid(path.Child("burst"), limit.Burst, "must be greater than or equal to 0"))
+	}
+	if cooldown := limit.RuleCooldown; cooldown != nil && cooldown.Duration <= 0 {
+		errs = append(errs, field.Invalid(path.Child("ruleCooldown"), cooldown.Duration.String(), "must be greater than 0"))
+	}
+
+	switch limit.Action {
+	case "", linev1alpha1.RateLimitDrop:
+	case linev1alpha1.RateLimitNotice:
+		if limit.Notice == "" {
+			errs = append(errs, field.Required(path.Child("notice"), "Notice action requires a notice"))
+		}
+	default:
+		errs = append(errs, field.NotSupported(path.Child("action"), limit.Action, supportedRateLimitActions))
+	}
+	return errs
+}
+
+func (s *Server) validateEvent(event *linev1alpha1.Event) field.ErrorList {
+	specPath := field.NewPath("spec")
+	errs := validation.ValidateEventSpec(&event.Spec, specPath)
+
+	if event.Spec.Selector == nil || event.Spec.Type != linebot.EventTypeMessage {
+		return errs
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(event.Spec.Selector)
+	if err != nil {
+		return append(errs, field.Invalid(specPath.Child("selector"), event.Spec.Selector, err.Error()))
+	}
+
+	eventBindings, err := s.clientset.LineV1alpha1().EventBindings(event.Namespace).List(metav1.ListOptions{
+		LabelSelector: selector.String(),
+	})
+	if err != nil {
+		return append(errs, field.InternalError(specPath.Child("selector"), err))
+	}
+
+	// Keywords can only be shared by events the resolution order tells apart,
+	// otherwise the reply would only depend on the event names.
+	binding := &linev1alpha1.Binding{
+		Name:     event.Name,
+		Type:     event.Spec.Type,
+		Messages: event.Spec.Messages,
+		Priority: event.Spec.Priority,
+	}
+
+	for _, eventBinding := range eventBindings.Items {
+		used := map[string]string{}
+		for i := range eventBinding.Subsets {
+			subset := &eventBinding.Subsets[i].Binding
+			if subset.Refers(linev1alpha1.EventOrigin, event.Name) || subset.Type != linebot.EventTypeMessage || !linev1alpha1.BindingTies(binding, subset) {
+				continue
+			}
+			for _, msg := range subset.Messages {
+				for _, keyword := range msg.Keywords {
+					used[keyword] = subset.Reference()
+				}
+			}
+		}
+
+		for i, msg := range event.Spec.Messages {
+			for j, keyword := range msg.Keywords {
+				if owner, ok := used[keyword]; ok {
+					errs = append(errs, field.Invalid(specPath.Child("messages").Index(i).Child("keywords").Index(j), keyword,
+						fmt.Sprintf("keyword is already used by Event %q with the same priority bound to Bot %q", owner, eventBinding.Name)))
+				}
+			}
+		}
+	}
+	return errs
+}
+
+func (s *Server) validateEventBinding(eventBinding *linev1alpha1.EventBinding) field.ErrorList {
+	errs := field.ErrorList{}
+	names := map[string]bool{}
+	for i, subset := range eventBinding.Subsets {
+		bindingPath := field.NewPath("subsets").Index(i).Child("binding")
+		if subset.Binding.Name == "" {
+			errs = append(errs, field.Required(bindingPath.Child("name"), "must reference an event"))
+		} else if names[subset.Binding.Reference()] {
+			errs = append(errs, field.Duplicate(bindingPath.Child("name"), subset.Binding.Name))
+		}
+		names[subset.Binding.Reference()] = true
+
+		switch subset.Binding.Origin {
+		case "", linev1alpha1.EventOrigin, linev1alpha1.ClusterEventOrigin:
+		default:
+			errs = append(errs, field.NotSupported(bindingPath.Child("origin"), subset.Binding.Origin,
+				[]string{string(linev1alpha1.EventOrigin), string(linev1alpha1.ClusterEventOrigin)}))
+		}
+
+		errs = append(errs, validation.ValidateEventType(subset.Binding.Type, bindingPath.Child("type"))...)
+		errs = append(errs, validation.ValidateMessages(subset.Binding.Type, subset.Binding.Messages, bindingPath.Child("messages"))...)
+	}
+	return errs
+}
+
+func (s *Server) validateClusterEvent(event *linev1alpha1.ClusterEvent) field.ErrorList {
+	specPath := field.NewPath("spec")
+	errs := validation.ValidateEventSpec(&event.Spec.EventSpec, specPath)
+
+	if event.Spec.Selector == nil {
+		errs = append(errs, field.Required(specPath.Child("selector"), "use {} to select all the eventbindings"))
+	} else if _, err := metav1.LabelSelectorAsSelector(event.Spec.Selector); err != nil {
+		errs = append(errs, field.Invalid(specPath.Child("selector"), event.Spec.Selector, err.Error()))
+	}
+
+	if _, err := metav1.LabelSelectorAsSelector(event.Spec.NamespaceSelector); err != nil {
+		errs = append(errs, field.Invalid(specPath.Child("namespaceSelector"), event.Spec.NamespaceSelector, err.Error()))
+	}
+	return errs
+}
+
+func (s *Server) validateDialog(dialog *linev1beta1.Dialog) field.ErrorList {
+	specPath := field.NewPath("spec")
+	errs := validation.ValidateDialogSpec(&dialog.Spec, specPath)
+	if dialog.Spec.Selector == nil {
+		errs = append(errs, field.Required(specPath.Child("selector"), ""))
+	} else if _, err := metav1.LabelSelectorAsSelector(dialog.Spec.Selector); err != nil {
+		errs = append(errs, field.Invalid(specPath.Child("selector"), dialog.Spec.Selector, err.Error()))
+	}
+	return errs
+}
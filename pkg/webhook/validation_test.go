@@ -0,0 +1,230 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	linev1alpha1 "github.com/kairen/line-bot-operator/pkg/apis/line/v1alpha1"
+	"github.com/kairen/line-bot-operator/pkg/constants"
+	"github.com/kairen/line-bot-operator/pkg/generated/clientset/versioned/fake"
+	opkit "github.com/kubedev/operator-kit"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+const testNamespace = "default"
+
+var monster = map[string]string{"hunter": "monster"}
+
+func newTestServer(lineObjects ...runtime.Object) *Server {
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "line-secret", Namespace: testNamespace},
+		Data: map[string][]byte{
+			constants.ChannelSecretKey: []byte("secret"),
+			constants.ChannelTokenKey:  []byte("token"),
+			constants.NgrokTokenKey:    []byte("ngrok"),
+		},
+	}
+	return &Server{
+		ctx:       &opkit.Context{Clientset: kubefake.NewSimpleClientset(secret)},
+		clientset: fake.NewSimpleClientset(lineObjects...),
+	}
+}
+
+func newBot(secretName string, expose linev1alpha1.BotExposeType) *linev1alpha1.Bot {
+	return &linev1alpha1.Bot{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-bot", Namespace: testNamespace},
+		Spec: linev1alpha1.BotSpec{
+			Selector:          &metav1.LabelSelector{MatchLabels: monster},
+			ChannelSecretName: secretName,
+			Expose:            linev1alpha1.BotExpose{Type: expose},
+		},
+	}
+}
+
+func newRequest(t *testing.T, op admissionv1beta1.Operation, kind string, obj, old runtime.Object) *admissionv1beta1.AdmissionRequest {
+	req := &admissionv1beta1.AdmissionRequest{
+		UID:       "test",
+		Kind:      metav1.GroupVersionKind{Group: linev1alpha1.CustomResourceGroup, Version: linev1alpha1.Version, Kind: kind},
+		Operation: op,
+	}
+
+	var err error
+	if req.Object.Raw, err = json.Marshal(obj); err != nil {
+		t.Fatal(err)
+	}
+	if old != nil {
+		if req.OldObject.Raw, err = json.Marshal(old); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return req
+}
+
+func TestValidate(t *testing.T) {
+	ingress := newBot("line-secret", linev1alpha1.IngressExpose)
+	ingress.Spec.Expose.DomainName = "bot.example.com"
+	deletedIngress := ingress.DeepCopy()
+	deletedIngress.Spec.ChannelSecretName = "deleted"
+
+	eventBinding := &linev1alpha1.EventBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-bot", Namespace: testNamespace, Labels: monster},
+		Subsets: []linev1alpha1.EventBindingSubset{{
+			Binding: linev1alpha1.Binding{
+				Name:     "hello",
+				Origin:   linev1alpha1.EventOrigin,
+				Type:     "message",
+				Messages: []linev1alpha1.Message{{Type: "text", Keywords: []string{"hello"}, Reply: "hello"}},
+			},
+		}},
+	}
+	newEvent := func(name string, priority int32) *linev1alpha1.Event {
+		return &linev1alpha1.Event{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+			Spec: linev1alpha1.EventSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: monster},
+				Type:     "message",
+				Priority: priority,
+				Messages: []linev1alpha1.Message{{Type: "text", Keywords: []string{"hello"}, Reply: name}},
+			},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		op      admissionv1beta1.Operation
+		kind    string
+		obj     runtime.Object
+		old     runtime.Object
+		allowed bool
+	}{
+		{
+			name:    "create bot",
+			op:      admissionv1beta1.Create,
+			kind:    "Bot",
+			obj:     newBot("line-secret", ""),
+			allowed: true,
+		},
+		{
+			name: "create bot without secret",
+			op:   admissionv1beta1.Create,
+			kind: "Bot",
+			obj:  newBot("missing", ""),
+		},
+		{
+			// The secret is gone, but the update doesn't reference it.
+			name:    "update bot with deleted secret",
+			op:      admissionv1beta1.Update,
+			kind:    "Bot",
+			obj:     newBot("deleted", linev1alpha1.NgrokExpose),
+			old:     newBot("deleted", ""),
+			allowed: true,
+		},
+		{
+			name: "update bot to missing secret",
+			op:   admissionv1beta1.Update,
+			kind: "Bot",
+			obj:  newBot("missing", ""),
+			old:  newBot("line-secret", ""),
+		},
+		{
+			name: "update bot expose with deleted secret",
+			op:   admissionv1beta1.Update,
+			kind: "Bot",
+			obj:  newBot("deleted", ""),
+			old:  deletedIngress,
+		},
+		{
+			name: "ingress without domain name",
+			op:   admissionv1beta1.Create,
+			kind: "Bot",
+			obj:  newBot("line-secret", linev1alpha1.IngressExpose),
+		},
+		{
+			name:    "ingress",
+			op:      admissionv1beta1.Create,
+			kind:    "Bot",
+			obj:     ingress,
+			allowed: true,
+		},
+		{
+			name:    "delete bot",
+			op:      admissionv1beta1.Delete,
+			kind:    "Bot",
+			obj:     newBot("missing", "unknown"),
+			allowed: true,
+		},
+		{
+			name: "event keyword already bound",
+			op:   admissionv1beta1.Create,
+			kind: "Event",
+			obj:  newEvent("hi", 0),
+		},
+		{
+			name:    "event keyword with another priority",
+			op:      admissionv1beta1.Create,
+			kind:    "Event",
+			obj:     newEvent("hi", 10),
+			allowed: true,
+		},
+		{
+			name:    "update bound event",
+			op:      admissionv1beta1.Update,
+			kind:    "Event",
+			obj:     newEvent("hello", 0),
+			old:     newEvent("hello", 0),
+			allowed: true,
+		},
+	}
+
+	s := newTestServer(eventBinding)
+	for _, test := range tests {
+		resp := s.validate(newRequest(t, test.op, test.kind, test.obj, test.old))
+		if resp.Allowed != test.allowed {
+			t.Errorf("%s: got allowed %v, want %v: %+v", test.name, resp.Allowed, test.allowed, resp.Result)
+		}
+		if !resp.Allowed && (resp.Result == nil || resp.Result.Reason != metav1.StatusReasonInvalid) {
+			t.Errorf("%s: got result %+v, want an Invalid status", test.name, resp.Result)
+		}
+	}
+}
+
+func TestServeValidate(t *testing.T) {
+	s := newTestServer()
+	review := &admissionv1beta1.AdmissionReview{
+		Request: newRequest(t, admissionv1beta1.Create, "Bot", newBot("missing", ""), nil),
+	}
+	body, err := json.Marshal(review)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, ValidatePath, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	s.serve(s.validate)(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	got := &admissionv1beta1.AdmissionReview{}
+	if err := json.Unmarshal(w.Body.Bytes(), got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Response == nil || got.Response.UID != "test" || got.Response.Allowed {
+		t.Errorf("got response %+v, want test denied", got.Response)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, ValidatePath, bytes.NewReader(body))
+	w = httptest.NewRecorder()
+	s.serve(s.validate)(w, req)
+	if w.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("got status %d without Content-Type, want %d", w.Code, http.StatusUnsupportedMediaType)
+	}
+}